@@ -1,13 +1,22 @@
 package robinhood
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoAccount is returned by DefaultAccount when the user has no active
+// account.
+var ErrNoAccount = errors.New("robinhood: no active account")
+
 type Account struct {
 	Meta
 	AccountNumber              string         `json:"account_number"`
-	BuyingPower                float64        `json:"buying_power,string"`
-	Cash                       float64        `json:"cash,string"`
-	CashAvailableForWithdrawal float64        `json:"cash_available_for_withdrawal,string"`
+	BuyingPower                Decimal        `json:"buying_power"`
+	Cash                       Decimal        `json:"cash"`
+	CashAvailableForWithdrawal Decimal        `json:"cash_available_for_withdrawal"`
 	CashBalances               CashBalances   `json:"cash_balances"`
-	CashHeldForOrders          float64        `json:"cash_held_for_orders,string"`
+	CashHeldForOrders          Decimal        `json:"cash_held_for_orders"`
 	Deactivated                bool           `json:"deactivated"`
 	DepositHalted              bool           `json:"deposit_halted"`
 	MarginBalances             MarginBalances `json:"margin_balances"`
@@ -19,38 +28,38 @@ type Account struct {
 	SmaHeldForOrders           interface{}    `json:"sma_held_for_orders"`
 	SweepEnabled               bool           `json:"sweep_enabled"`
 	Type                       string         `json:"type"`
-	UnclearedDeposits          float64        `json:"uncleared_deposits,string"`
-	UnsettledFunds             float64        `json:"unsettled_funds,string"`
+	UnclearedDeposits          Decimal        `json:"uncleared_deposits"`
+	UnsettledFunds             Decimal        `json:"unsettled_funds"`
 	User                       string         `json:"user"`
 	WithdrawalHalted           bool           `json:"withdrawal_halted"`
 }
 
 type CashBalances struct {
 	Meta
-	BuyingPower                float64 `json:"buying_power,string"`
-	Cash                       float64 `json:"cash,string"`
-	CashAvailableForWithdrawal float64 `json:"cash_available_for_withdrawal,string"`
-	CashHeldForOrders          float64 `json:"cash_held_for_orders,string"`
-	UnclearedDeposits          float64 `json:"uncleared_deposits,string"`
-	UnsettledFunds             float64 `json:"unsettled_funds,string"`
+	BuyingPower                Decimal `json:"buying_power"`
+	Cash                       Decimal `json:"cash"`
+	CashAvailableForWithdrawal Decimal `json:"cash_available_for_withdrawal"`
+	CashHeldForOrders          Decimal `json:"cash_held_for_orders"`
+	UnclearedDeposits          Decimal `json:"uncleared_deposits"`
+	UnsettledFunds             Decimal `json:"unsettled_funds"`
 }
 
 type MarginBalances struct {
 	Meta
-	Cash                              float64 `json:"cash,string"`
-	CashAvailableForWithdrawal        float64 `json:"cash_available_for_withdrawal,string"`
-	CashHeldForOrders                 float64 `json:"cash_held_for_orders,string"`
-	DayTradeBuyingPower               float64 `json:"day_trade_buying_power,string"`
-	DayTradeBuyingPowerHeldForOrders  float64 `json:"day_trade_buying_power_held_for_orders,string"`
-	DayTradeRatio                     float64 `json:"day_trade_ratio,string"`
-	MarginLimit                       float64 `json:"margin_limit,string"`
+	Cash                              Decimal `json:"cash"`
+	CashAvailableForWithdrawal        Decimal `json:"cash_available_for_withdrawal"`
+	CashHeldForOrders                 Decimal `json:"cash_held_for_orders"`
+	DayTradeBuyingPower               Decimal `json:"day_trade_buying_power"`
+	DayTradeBuyingPowerHeldForOrders  Decimal `json:"day_trade_buying_power_held_for_orders"`
+	DayTradeRatio                     Decimal `json:"day_trade_ratio"`
+	MarginLimit                       Decimal `json:"margin_limit"`
 	MarkedPatternDayTraderDate        string  `json:"marked_pattern_day_trader_date"`
-	OvernightBuyingPower              float64 `json:"overnight_buying_power,string"`
-	OvernightBuyingPowerHeldForOrders float64 `json:"overnight_buying_power_held_for_orders,string"`
-	OvernightRatio                    float64 `json:"overnight_ratio,string"`
-	UnallocatedMarginCash             float64 `json:"unallocated_margin_cash,string"`
-	UnclearedDeposits                 float64 `json:"uncleared_deposits,string"`
-	UnsettledFunds                    float64 `json:"unsettled_funds,string"`
+	OvernightBuyingPower              Decimal `json:"overnight_buying_power"`
+	OvernightBuyingPowerHeldForOrders Decimal `json:"overnight_buying_power_held_for_orders"`
+	OvernightRatio                    Decimal `json:"overnight_ratio"`
+	UnallocatedMarginCash             Decimal `json:"unallocated_margin_cash"`
+	UnclearedDeposits                 Decimal `json:"uncleared_deposits"`
+	UnsettledFunds                    Decimal `json:"unsettled_funds"`
 }
 
 type GetAccountsResponse struct {
@@ -62,11 +71,50 @@ func (resp *GetAccountsResponse) Details() string {
 	return resp.Detail
 }
 
+// GetAccounts returns all the accounts associated with a client's
+// credentials.
 func (c *Client) GetAccounts() ([]Account, error) {
-	var r GetAccountsResponse
-	err := c.GetAndDecode(epAccounts, &r)
+	return c.GetAccountsContext(context.Background())
+}
+
+// GetAccountsContext is like GetAccounts but the request is bound to ctx.
+func (c *Client) GetAccountsContext(ctx context.Context) ([]Account, error) {
+	return NewPager[Account](c, c.url(epAccounts)).All(ctx)
+}
+
+func (resp *Account) Details() string {
+	return ""
+}
+
+// GetAccount returns the account with the given account number.
+func (c *Client) GetAccount(number string) (Account, error) {
+	return c.GetAccountContext(context.Background(), number)
+}
+
+// GetAccountContext is like GetAccount but the request is bound to ctx.
+func (c *Client) GetAccountContext(ctx context.Context, number string) (Account, error) {
+	var a Account
+	err := c.GetAndDecodeContext(ctx, c.url(epAccounts+number+"/"), &a)
+	return a, err
+}
+
+// DefaultAccount returns the first of the user's accounts that hasn't been
+// deactivated, or ErrNoAccount if there is none.
+func (c *Client) DefaultAccount() (Account, error) {
+	return c.DefaultAccountContext(context.Background())
+}
+
+// DefaultAccountContext is like DefaultAccount but the request is bound to
+// ctx.
+func (c *Client) DefaultAccountContext(ctx context.Context) (Account, error) {
+	accts, err := c.GetAccountsContext(ctx)
 	if err != nil {
-		return nil, err
+		return Account{}, err
+	}
+	for _, a := range accts {
+		if !a.Deactivated {
+			return a, nil
+		}
 	}
-	return r.Results, err
+	return Account{}, ErrNoAccount
 }
@@ -0,0 +1,114 @@
+package robinhood
+
+import (
+	"context"
+	"net/url"
+)
+
+// An AccountClient is a view of a Client bound to one account. Its methods
+// fill in the account's URLs, so callers don't have to thread them through
+// each request.
+type AccountClient struct {
+	Client  *Client
+	Account Account
+
+	// GuardDayTrades makes SendOrder refuse, with ErrDayTradeLimit, a sale
+	// that would be a day trade when the account has no day trades
+	// remaining; see RemainingDayTrades. Orders with OverrideDayTradeChecks
+	// set are not checked.
+	GuardDayTrades bool
+}
+
+// ForAccount returns an AccountClient for a.
+func (c *Client) ForAccount(a Account) *AccountClient {
+	return &AccountClient{Client: c, Account: a}
+}
+
+// DefaultAccountClient returns an AccountClient for the DefaultAccount.
+func (c *Client) DefaultAccountClient() (*AccountClient, error) {
+	return c.DefaultAccountClientContext(context.Background())
+}
+
+// DefaultAccountClientContext is like DefaultAccountClient but the request is
+// bound to ctx.
+func (c *Client) DefaultAccountClientContext(ctx context.Context) (*AccountClient, error) {
+	a, err := c.DefaultAccountContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.ForAccount(a), nil
+}
+
+// GetPositions returns the account's positions.
+func (c *AccountClient) GetPositions() ([]Position, error) {
+	return c.GetPositionsContext(context.Background())
+}
+
+// GetPositionsContext is like GetPositions but the request is bound to ctx.
+func (c *AccountClient) GetPositionsContext(ctx context.Context) ([]Position, error) {
+	return c.Client.GetPositionsContext(ctx, c.Account)
+}
+
+// GetPortfolio returns the account's portfolio.
+func (c *AccountClient) GetPortfolio() (Portfolio, error) {
+	return c.GetPortfolioContext(context.Background())
+}
+
+// GetPortfolioContext is like GetPortfolio but the request is bound to ctx.
+func (c *AccountClient) GetPortfolioContext(ctx context.Context) (Portfolio, error) {
+	return c.Client.GetPortfolioContext(ctx, c.Account)
+}
+
+// GetOrders returns the account's orders, most recent first. If i is not
+// nil, only orders for that instrument are returned.
+func (c *AccountClient) GetOrders(i *Instrument) ([]Order, error) {
+	return c.GetOrdersContext(context.Background(), i)
+}
+
+// GetOrdersContext is like GetOrders but every page request is bound to ctx.
+func (c *AccountClient) GetOrdersContext(ctx context.Context, i *Instrument) ([]Order, error) {
+	params := url.Values{}
+	if i != nil {
+		params.Set("instrument", i.URL)
+	}
+	return c.orders(ctx, params)
+}
+
+// orders returns the account's orders selected by the query params.
+func (c *AccountClient) orders(ctx context.Context, params url.Values) ([]Order, error) {
+	u := c.Client.url(epOrders)
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	var orders []Order
+	p := NewPager[Order](c.Client, u)
+	for p.HasNext() {
+		results, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range results {
+			if o.Account == c.Account.URL {
+				orders = append(orders, o)
+			}
+		}
+	}
+	return orders, nil
+}
+
+// SendOrder sends an order for the account, setting the request's Account.
+func (c *AccountClient) SendOrder(request *OrderRequest) (Order, error) {
+	return c.SendOrderContext(context.Background(), request)
+}
+
+// SendOrderContext is like SendOrder but the request is bound to ctx.
+func (c *AccountClient) SendOrderContext(ctx context.Context, request *OrderRequest) (Order, error) {
+	request.Account = c.Account.URL
+	if c.GuardDayTrades {
+		if err := c.checkDayTrade(ctx, request); err != nil {
+			return Order{}, err
+		}
+	}
+	return c.Client.SendOrderContext(ctx, request)
+}
@@ -0,0 +1,234 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ACH endpoints, relative to the API base URL.
+const (
+	epACHRelationships = "ach/relationships/"
+	epACHTransfers     = "ach/transfers/"
+)
+
+// DefaultTransferPollInterval is how often WaitForTransfer checks a
+// transfer's state if no interval is given.
+const DefaultTransferPollInterval = time.Minute
+
+// An ACHRelationship is a bank account linked to a Robinhood account.
+type ACHRelationship struct {
+	ID                    string `json:"id"`
+	Account               string `json:"account"`
+	BankAccountHolderName string `json:"bank_account_holder_name"`
+	BankAccountNickname   string `json:"bank_account_nickname"`
+	// The last four digits of the bank account number.
+	BankAccountNumber   string    `json:"bank_account_number"`
+	BankAccountType     string    `json:"bank_account_type"`
+	BankRoutingNumber   string    `json:"bank_routing_number"`
+	State               string    `json:"state"`
+	Verified            bool      `json:"verified"`
+	VerifyMicroDeposits string    `json:"verify_micro_deposits"`
+	WithdrawalLimit     Decimal   `json:"withdrawal_limit"`
+	Unlink              string    `json:"unlink"`
+	UnlinkedAt          time.Time `json:"unlinked_at"`
+	CreatedAt           time.Time `json:"created_at"`
+	URL                 string    `json:"url"`
+}
+
+type TransferDirection string
+
+const (
+	TransferDirection_Deposit  TransferDirection = "deposit"
+	TransferDirection_Withdraw TransferDirection = "withdraw"
+)
+
+type TransferState string
+
+const (
+	TransferState_Pending   TransferState = "pending"
+	TransferState_Requested TransferState = "requested"
+	TransferState_Approved  TransferState = "approved"
+	TransferState_Completed TransferState = "completed"
+	TransferState_Cancelled TransferState = "cancelled"
+	TransferState_Failed    TransferState = "failed"
+	TransferState_Reversed  TransferState = "reversed"
+)
+
+// Settled returns whether a transfer in state s has finished, successfully
+// or not, and will not change state again.
+func (s TransferState) Settled() bool {
+	switch s {
+	case TransferState_Completed, TransferState_Cancelled, TransferState_Failed, TransferState_Reversed:
+		return true
+	}
+	return false
+}
+
+// An ACHTransfer is a deposit from or withdrawal to a linked bank account.
+type ACHTransfer struct {
+	ID                  string            `json:"id"`
+	ACHRelationship     string            `json:"ach_relationship"`
+	Amount              Decimal           `json:"amount"`
+	Direction           TransferDirection `json:"direction"`
+	State               TransferState     `json:"state"`
+	Fees                Decimal           `json:"fees"`
+	EarlyAccessAmount   Decimal           `json:"early_access_amount"`
+	ExpectedLandingDate string            `json:"expected_landing_date"`
+	Scheduled           bool              `json:"scheduled"`
+	StatusDescription   string            `json:"status_description"`
+	// The URL to POST to to cancel the transfer, if it can be cancelled.
+	Cancel    string    `json:"cancel"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url"`
+	Detail    string    `json:"detail"`
+}
+
+func (resp *ACHTransfer) Details() string {
+	return resp.Detail
+}
+
+type TransferRequest struct {
+	// URL of the Account
+	Account string `json:"account,omitempty"`
+	// URL of the ACHRelationship
+	ACHRelationship string            `json:"ach_relationship"`
+	Amount          Decimal           `json:"amount"`
+	Direction       TransferDirection `json:"direction"`
+	// Client-generated unique id for the transfer, e.g. a UUID. When set,
+	// the transfer is sent with it as an idempotency key so it may safely be
+	// retried.
+	RefID string `json:"ref_id,omitempty"`
+}
+
+// GetACHRelationships returns the bank accounts linked to the user's
+// accounts.
+func (c *Client) GetACHRelationships() ([]ACHRelationship, error) {
+	return c.GetACHRelationshipsContext(context.Background())
+}
+
+// GetACHRelationshipsContext is like GetACHRelationships but every page
+// request is bound to ctx.
+func (c *Client) GetACHRelationshipsContext(ctx context.Context) ([]ACHRelationship, error) {
+	return NewPager[ACHRelationship](c, c.url(epACHRelationships)).All(ctx)
+}
+
+// GetTransfers returns the user's ACH transfers, most recent first.
+func (c *Client) GetTransfers() ([]ACHTransfer, error) {
+	return c.GetTransfersContext(context.Background())
+}
+
+// GetTransfersContext is like GetTransfers but every page request is bound
+// to ctx.
+func (c *Client) GetTransfersContext(ctx context.Context) ([]ACHTransfer, error) {
+	return NewPager[ACHTransfer](c, c.url(epACHTransfers)).All(ctx)
+}
+
+// GetTransfer returns the ACH transfer with the given id.
+func (c *Client) GetTransfer(id string) (ACHTransfer, error) {
+	return c.GetTransferContext(context.Background(), id)
+}
+
+// GetTransferContext is like GetTransfer but the request is bound to ctx.
+func (c *Client) GetTransferContext(ctx context.Context, id string) (ACHTransfer, error) {
+	var t ACHTransfer
+	err := c.GetAndDecodeContext(ctx, c.url(epACHTransfers+id+"/"), &t)
+	return t, err
+}
+
+// SendTransfer initiates an ACH transfer.
+func (c *Client) SendTransfer(request *TransferRequest) (ACHTransfer, error) {
+	return c.SendTransferContext(context.Background(), request)
+}
+
+// SendTransferContext is like SendTransfer but the request is bound to ctx.
+func (c *Client) SendTransferContext(ctx context.Context, request *TransferRequest) (ACHTransfer, error) {
+	if request.Amount.Sign() <= 0 {
+		return ACHTransfer{}, fmt.Errorf("robinhood: transfer amount must be positive, not %s", request.Amount)
+	}
+	if request.RefID != "" && idempotencyKey(ctx) == "" {
+		ctx = WithIdempotencyKey(ctx, request.RefID)
+	}
+	var t ACHTransfer
+	err := c.PostAndDecodeContext(ctx, c.url(epACHTransfers), request, &t)
+	return t, err
+}
+
+// CancelTransfer cancels the ACH transfer with the given id. Only transfers
+// that haven't been sent to the bank yet can be cancelled.
+func (c *Client) CancelTransfer(id string) error {
+	return c.CancelTransferContext(context.Background(), id)
+}
+
+// CancelTransferContext is like CancelTransfer but the request is bound to
+// ctx.
+func (c *Client) CancelTransferContext(ctx context.Context, id string) error {
+	var t ACHTransfer
+	return c.PostAndDecodeContext(ctx, c.url(epACHTransfers+id+"/cancel/"), struct{}{}, &t)
+}
+
+// WaitForTransfer polls the ACH transfer with the given id every interval
+// until it settles or ctx is done, and returns it in its final state. An
+// interval of 0 means DefaultTransferPollInterval. ACH transfers take days
+// to settle, so ctx should usually not have a short deadline.
+func (c *Client) WaitForTransfer(ctx context.Context, id string, interval time.Duration) (ACHTransfer, error) {
+	if interval <= 0 {
+		interval = DefaultTransferPollInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		tr, err := c.GetTransferContext(ctx, id)
+		if err != nil || tr.State.Settled() {
+			return tr, err
+		}
+		select {
+		case <-ctx.Done():
+			return tr, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Deposit transfers amount from the linked bank account rel into the
+// account.
+func (c *AccountClient) Deposit(rel ACHRelationship, amount Decimal) (ACHTransfer, error) {
+	return c.DepositContext(context.Background(), rel, amount)
+}
+
+// DepositContext is like Deposit but the request is bound to ctx.
+func (c *AccountClient) DepositContext(ctx context.Context, rel ACHRelationship, amount Decimal) (ACHTransfer, error) {
+	return c.Client.SendTransferContext(ctx, &TransferRequest{
+		Account:         c.Account.URL,
+		ACHRelationship: rel.URL,
+		Amount:          amount,
+		Direction:       TransferDirection_Deposit,
+	})
+}
+
+// Withdraw transfers amount from the account to the linked bank account
+// rel. It fails without making the transfer if the account's
+// CashAvailableForWithdrawal, which excludes uncleared deposits, is less
+// than amount.
+func (c *AccountClient) Withdraw(rel ACHRelationship, amount Decimal) (ACHTransfer, error) {
+	return c.WithdrawContext(context.Background(), rel, amount)
+}
+
+// WithdrawContext is like Withdraw but the requests are bound to ctx.
+func (c *AccountClient) WithdrawContext(ctx context.Context, rel ACHRelationship, amount Decimal) (ACHTransfer, error) {
+	a, err := c.Client.GetAccountContext(ctx, c.Account.AccountNumber)
+	if err != nil {
+		return ACHTransfer{}, err
+	}
+	if a.CashAvailableForWithdrawal.Cmp(amount) < 0 {
+		return ACHTransfer{}, fmt.Errorf("robinhood: cannot withdraw %s, only %s is available (%s in uncleared deposits)",
+			amount, a.CashAvailableForWithdrawal, a.UnclearedDeposits)
+	}
+	return c.Client.SendTransferContext(ctx, &TransferRequest{
+		Account:         c.Account.URL,
+		ACHRelationship: rel.URL,
+		Amount:          amount,
+		Direction:       TransferDirection_Withdraw,
+	})
+}
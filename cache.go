@@ -0,0 +1,276 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockRetry is how often a held cache lock is retried.
+const lockRetry = 50 * time.Millisecond
+
+// A CredsCacher takes user credentials and a file path. The token obtained
+// from the RobinHood API will be cached at the file path, along with its
+// expiry and refresh token, and a new token will not be obtained until it
+// expires or is rejected.
+//
+// Processes sharing the file coordinate through a lock file next to it, so
+// that only one of them logs in, and the file is replaced atomically. The
+// lock is held with the operating system's file locking, so it is released
+// if its holder dies; the lock file itself is left in place.
+//
+// If Key or Passphrase is set, the cached token is encrypted with AES-GCM. A
+// plaintext cache left by an earlier version is read and then rewritten
+// encrypted.
+//
+// If Creds is a *Creds without a DeviceToken, or SourceCreds, the device
+// token is kept in the cache too, so that every login through the
+// CredsCacher presents the same device, even after Logout.
+type CredsCacher struct {
+	Creds TokenGetter
+	Path  string
+
+	// Key is a 16, 24 or 32 byte AES key for encrypting the cache.
+	Key []byte
+	// Passphrase, if Key is not set, is used to derive the encryption key
+	// with scrypt.
+	Passphrase string
+}
+
+// cachedToken is the content of a cache file.
+type cachedToken struct {
+	OAuthToken
+	Username    string `json:"username,omitempty"`
+	DeviceToken string `json:"device_token,omitempty"`
+}
+
+// GetToken implements TokenGetter. It may fail if an error is encountered
+// checking the file path provided, or if the underlying creds return an error
+// when retrieving their token.
+func (c *CredsCacher) GetToken() (string, error) {
+	return c.GetTokenContext(context.Background())
+}
+
+// GetTokenContext implements ContextTokenGetter.
+func (c *CredsCacher) GetTokenContext(ctx context.Context) (string, error) {
+	tok, err := c.Login(ctx)
+	if err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
+
+// Login implements TokenRefresher. It returns the cached token if it has not
+// expired, and otherwise refreshes it or logs in with Creds, caching the
+// result.
+func (c *CredsCacher) Login(ctx context.Context) (*OAuthToken, error) {
+	return c.renew(ctx, nil)
+}
+
+// Refresh implements TokenRefresher. If another process has already replaced
+// tok in the cache, its token is returned. Otherwise the cached token is
+// refreshed if Creds supports it, or Creds log in again.
+func (c *CredsCacher) Refresh(ctx context.Context, tok *OAuthToken) (*OAuthToken, error) {
+	return c.renew(ctx, tok)
+}
+
+// renew returns a valid token from the cache, obtaining a new one if the
+// cached token is missing, expired, for another user, or the same as stale.
+func (c *CredsCacher) renew(ctx context.Context, stale *OAuthToken) (*OAuthToken, error) {
+	err := os.MkdirAll(filepath.Dir(c.Path), 0750)
+	if err != nil {
+		return nil, fmt.Errorf("error creating path for token: %s", err)
+	}
+
+	unlock, err := c.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	cached, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && c.usable(cached, stale) {
+		return &cached.OAuthToken, nil
+	}
+
+	device, err := c.deviceToken(cached)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := c.obtain(ctx, cached)
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken == "" {
+		return nil, fmt.Errorf("Empty token")
+	}
+
+	u := c.username()
+	if u == "" && cached != nil {
+		// The token was refreshed without the username being known.
+		u = cached.Username
+	}
+	err = c.write(&cachedToken{OAuthToken: *tok, Username: u, DeviceToken: device})
+	return tok, err
+}
+
+// usable returns whether the cached token may be returned instead of stale.
+func (c *CredsCacher) usable(cached *cachedToken, stale *OAuthToken) bool {
+	if cached.AccessToken == "" || (stale != nil && cached.AccessToken == stale.AccessToken) {
+		return false
+	}
+	if u := c.username(); u != "" && cached.Username != "" && cached.Username != u {
+		return false
+	}
+	return !cached.Expired(tokenRefreshLeeway)
+}
+
+// obtain gets a new token from Creds, refreshing the cached token if
+// possible and logging in otherwise.
+func (c *CredsCacher) obtain(ctx context.Context, cached *cachedToken) (*OAuthToken, error) {
+	r, ok := c.Creds.(TokenRefresher)
+	if !ok {
+		tkn, err := getToken(ctx, c.Creds)
+		if err != nil {
+			return nil, err
+		}
+		return &OAuthToken{AccessToken: tkn}, nil
+	}
+
+	u := c.username()
+	if cached != nil && cached.RefreshToken != "" && (cached.Username == "" || u == "" || cached.Username == u) {
+		if tok, err := r.Refresh(ctx, &cached.OAuthToken); err == nil {
+			return tok, nil
+		}
+	}
+	return r.Login(ctx)
+}
+
+// deviceToken returns the device token to cache, giving Creds the cached one
+// or a new one if they have none. It is empty if Creds don't use a device
+// token.
+func (c *CredsCacher) deviceToken(cached *cachedToken) (string, error) {
+	var tkn string
+	if cached != nil {
+		tkn = cached.DeviceToken
+	}
+	switch creds := c.Creds.(type) {
+	case *Creds:
+		if creds.DeviceToken == "" {
+			creds.DeviceToken = tkn
+		}
+		if err := creds.ensureDeviceToken(); err != nil {
+			return "", err
+		}
+		return creds.DeviceToken, nil
+	case *SourceCreds:
+		return creds.deviceToken(tkn)
+	}
+	return "", nil
+}
+
+// username returns the username of Creds, if known.
+func (c *CredsCacher) username() string {
+	switch creds := c.Creds.(type) {
+	case *Creds:
+		return creds.Username
+	case *SourceCreds:
+		return creds.username()
+	}
+	return ""
+}
+
+// read returns the cached token, or nil if there is none. A plaintext cache
+// is rewritten encrypted if encryption is configured.
+func (c *CredsCacher) read() (*cachedToken, error) {
+	bs, err := ioutil.ReadFile(c.Path)
+	if os.IsNotExist(err) || (err == nil && len(bs) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, plain, err := c.open(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedToken
+	if json.Unmarshal(data, &cached) != nil {
+		// Earlier versions cached only the access token.
+		cached = cachedToken{OAuthToken: OAuthToken{AccessToken: string(data)}}
+	}
+
+	if plain && c.encrypted() {
+		if err := c.write(&cached); err != nil {
+			return nil, err
+		}
+	}
+	return &cached, nil
+}
+
+// write atomically replaces the cache file with tok, encrypting it if
+// configured to.
+func (c *CredsCacher) write(tok *cachedToken) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	if c.encrypted() {
+		data, err = c.seal(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(c.Path), filepath.Base(c.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.Path)
+}
+
+// lock acquires the cache's lock file, waiting for any other holder until
+// ctx is done. The returned func releases it.
+func (c *CredsCacher) lock(ctx context.Context) (func(), error) {
+	lockPath := c.Path + ".lock"
+	for {
+		unlock, err := tryLockFile(lockPath)
+		if err != nil || unlock != nil {
+			return unlock, err
+		}
+
+		t := time.NewTimer(lockRetry)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
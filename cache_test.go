@@ -0,0 +1,127 @@
+package robinhood_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+)
+
+// countingCreds is a TokenRefresher that issues numbered tokens, waiting
+// for release, if set, before each login.
+type countingCreds struct {
+	mu      sync.Mutex
+	logins  int
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *countingCreds) GetToken() (string, error) {
+	tok, err := c.Login(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
+
+func (c *countingCreds) Login(ctx context.Context) (*robinhood.OAuthToken, error) {
+	if c.entered != nil {
+		c.entered <- struct{}{}
+	}
+	if c.release != nil {
+		<-c.release
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logins++
+	return &robinhood.OAuthToken{AccessToken: fmt.Sprint("token-", c.logins), Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func (c *countingCreds) Refresh(ctx context.Context, tok *robinhood.OAuthToken) (*robinhood.OAuthToken, error) {
+	return c.Login(ctx)
+}
+
+func TestCredsCacherConcurrentLogin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	creds := &countingCreds{}
+
+	var wg sync.WaitGroup
+	toks := make([]string, 10)
+	errs := make([]error, len(toks))
+	for i := range toks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := &robinhood.CredsCacher{Creds: creds, Path: path}
+			tok, err := c.Login(context.Background())
+			if err == nil {
+				toks[i] = tok.AccessToken
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range toks {
+		if errs[i] != nil || toks[i] != "token-1" {
+			t.Errorf("Login %d: got %q, %v, want token-1", i, toks[i], errs[i])
+		}
+	}
+	if creds.logins != 1 {
+		t.Errorf("%d logins, want 1", creds.logins)
+	}
+}
+
+func TestCredsCacherLockNotBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	creds := &countingCreds{entered: make(chan struct{}), release: make(chan struct{})}
+	c := &robinhood.CredsCacher{Creds: creds, Path: path}
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Login(context.Background())
+		done <- err
+	}()
+	<-creds.entered
+	creds.entered = nil
+
+	// However old the lock file looks, it is held.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := c.Login(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Login while locked: got %v, want context.DeadlineExceeded", err)
+	}
+
+	close(creds.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	tok, err := c.Login(context.Background())
+	if err != nil || tok.AccessToken != "token-1" {
+		t.Errorf("Login after unlock: got %v, %v, want the cached token-1", tok, err)
+	}
+}
+
+func TestCredsCacherLeftoverLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path+".lock", []byte("12345\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &robinhood.CredsCacher{Creds: &countingCreds{}, Path: path}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.Login(ctx); err != nil {
+		t.Errorf("Login: %v", err)
+	}
+}
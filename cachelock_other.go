@@ -0,0 +1,22 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package robinhood
+
+import "os"
+
+// tryLockFile creates the lock file at path exclusively. It returns a func
+// that releases the lock by removing the file, or nil if the file exists.
+//
+// Without file locking, a lock file left by a process that died holding it
+// is never broken, and must be removed by hand.
+func tryLockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return func() { os.Remove(path) }, nil
+}
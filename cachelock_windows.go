@@ -0,0 +1,28 @@
+package robinhood
+
+import (
+	"os"
+	"syscall"
+)
+
+// errSharingViolation is ERROR_SHARING_VIOLATION, returned when opening a
+// file another handle has opened without sharing.
+const errSharingViolation syscall.Errno = 32
+
+// tryLockFile opens the lock file at path without sharing it with any other
+// handle. It returns a func that releases the lock, or nil if another handle
+// has the file open.
+func tryLockFile(path string) (func(), error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(p, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err == errSharingViolation {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return func() { syscall.CloseHandle(h) }, nil
+}
@@ -1,114 +1,262 @@
 package robinhood
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
-	"strings"
 	"time"
 
-	"astuart.co/clyde"
+	"golang.org/x/time/rate"
 )
 
+// Endpoints, relative to the API base URL.
 const (
 	epBase        = "https://api.robinhood.com/"
-	epLogin       = epBase + "oauth2/token/"
-	epAccounts    = epBase + "accounts/"
-	epQuotes      = epBase + "quotes/"
-	epPortfolios  = epBase + "portfolios/"
-	epWatchlists  = epBase + "watchlists/"
-	epInstruments = epBase + "instruments/"
-	epOrders      = epBase + "orders/"
+	epLogin       = "oauth2/token/"
+	epAccounts    = "accounts/"
+	epQuotes      = "quotes/"
+	epPortfolios  = "portfolios/"
+	epWatchlists  = "watchlists/"
+	epInstruments = "instruments/"
+	epOrders      = "orders/"
+	epChallenge   = "challenge/"
+	epRevoke      = "oauth2/revoke_token/"
 )
 
 type Client struct {
+	// Token is the access token the Client was dialed with. See AccessToken
+	// for the token currently in use.
 	Token string
 	*http.Client
+
+	auth   *authTransport
+	tokens TokenGetter
+
+	baseURL   string
+	header    http.Header
+	transport http.RoundTripper
+	timeout   time.Duration
+	retry     RetryPolicy
+	logger    Logger
+
+	limiter        *rate.Limiter
+	familyLimiters map[string]*rate.Limiter
 }
 
-func Dial(t TokenGetter) (*Client, error) {
-	tkn, err := t.GetToken()
+// Dial obtains a token from t and returns a Client authenticated with it,
+// configured by any options given. If t logs in with Creds, the login
+// requests are made with the Client's options too; see Creds.HTTPClient. If
+// a request is rejected as unauthorized, e.g. because the token was revoked,
+// the Client obtains a new token from t and retries the request once.
+func Dial(t TokenGetter, opts ...Option) (*Client, error) {
+	return DialContext(context.Background(), t, opts...)
+}
+
+// DialContext is like Dial but ctx bounds the token retrieval when t
+// implements ContextTokenGetter.
+func DialContext(ctx context.Context, t TokenGetter, opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL:   epBase,
+		header:    http.Header{},
+		transport: http.DefaultTransport,
+		limiter:   rate.NewLimiter(DefaultRateLimit, DefaultRateBurst),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r, ok := t.(TokenRefresher)
+	if !ok {
+		r = getterRefresher{t}
+	}
+	tok, err := r.Login(withLoggingInClient(ctx, c))
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		Token:  tkn,
-		Client: &http.Client{Transport: clyde.HeaderRoundTripper{"Authorization": "Bearer " + tkn}},
-	}, nil
+	c.Token = tok.AccessToken
+	c.auth = &authTransport{tok: tok, refresher: r, client: c, next: &limitTransport{client: c, next: newLoggingTransport(c.logger, c.transport)}}
+	c.tokens = t
+	c.Client = &http.Client{
+		Transport: &headerTransport{header: c.header, next: c.auth},
+		Timeout:   c.timeout,
+	}
+	return c, nil
 }
 
-func (c *Client) GetAndDecode(url string, dest Detailable) error {
-	res, err := c.Get(url)
-	if err != nil {
-		return err
+// loginClient returns an http.Client for requests that must not carry the
+// Client's token, such as logging in, made with the Client's transport,
+// timeout, headers and rate limits. They are logged to l, or the Client's
+// Logger if l is nil.
+func (c *Client) loginClient(l Logger) *http.Client {
+	if l == nil {
+		l = c.logger
 	}
-	defer res.Body.Close()
+	return &http.Client{
+		Transport: &headerTransport{header: c.header, next: &limitTransport{client: c, next: newLoggingTransport(l, c.transport)}},
+		Timeout:   c.timeout,
+	}
+}
+
+// AccessToken returns the access token the Client currently authenticates
+// with, which differs from Token once the token has been renewed. It is empty
+// after Logout.
+func (c *Client) AccessToken() string {
+	tok := c.auth.current()
+	if tok == nil {
+		return ""
+	}
+	return tok.AccessToken
+}
 
-	return unmarshalJSON(res.Body, dest)
+// url resolves the endpoint ep against the Client's base URL.
+func (c *Client) url(ep string) string {
+	return c.baseURL + ep
+}
+
+// GetAndDecode retrieves from the given URL and decodes the JSON response
+// into dest.
+func (c *Client) GetAndDecode(url string, dest Detailable) error {
+	return c.GetAndDecodeContext(context.Background(), url, dest)
+}
+
+// GetAndDecodeContext is like GetAndDecode but the request is bound to ctx.
+func (c *Client) GetAndDecodeContext(ctx context.Context, url string, dest Detailable) error {
+	return c.do(ctx, http.MethodGet, url, nil, dest)
 }
 
 type Detailable interface {
 	Details() string
 }
 
+// PostAndDecode posts data as JSON to the given URL and decodes the JSON
+// response into dest.
 func (c *Client) PostAndDecode(url string, data interface{}, dest Detailable) error {
-	bytes, err := json.Marshal(data)
+	return c.PostAndDecodeContext(context.Background(), url, data, dest)
+}
+
+// PostAndDecodeContext is like PostAndDecode but the request is bound to ctx.
+// The request is only retried if ctx carries an idempotency key.
+func (c *Client) PostAndDecodeContext(ctx context.Context, url string, data interface{}, dest Detailable) error {
+	body, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
-	if DebugMode {
-		fmt.Println(string(bytes))
+	return c.do(ctx, http.MethodPost, url, body, dest)
+}
+
+// do sends a request and decodes its response into dest, retrying according
+// to the Client's RetryPolicy.
+func (c *Client) do(ctx context.Context, method, url string, body []byte, dest Detailable) error {
+	key := idempotencyKey(ctx)
+	retryable := method == http.MethodGet || key != ""
+
+	if c.auth.current() == nil {
+		return ErrLoggedOut
 	}
-	res, err := c.Post(url, "application/json", strings.NewReader(string(bytes)))
+
+	var err error
+	attempt := 0
+	for {
+		attempt++
+		var req *http.Request
+		req, err = newRequest(ctx, method, url, body)
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+
+		err = c.doOnce(req, dest)
+		if err == nil || !retryable || attempt >= c.retry.MaxAttempts || !shouldRetry(ctx, err) {
+			break
+		}
+
+		t := time.NewTimer(c.retry.delay(attempt, err))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return &RetryError{Attempts: attempt, Err: ctx.Err()}
+		case <-t.C:
+		}
+	}
+
+	if err != nil && attempt > 1 {
+		return &RetryError{Attempts: attempt, Err: err}
+	}
+	return err
+}
+
+// doOnce sends req and decodes its response into dest.
+func (c *Client) doOnce(req *http.Request, dest Detailable) error {
+	res, err := c.Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	return unmarshalJSON(res.Body, dest)
+	return decodeResponse(res, dest)
 }
 
-func unauthenticatedPostAndDecode(url string, data interface{}, dest Detailable) error {
-	bytes, err := json.Marshal(data)
+// newRequest builds a request, with body sent as JSON if it is not nil.
+func newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	if body == nil {
+		return http.NewRequestWithContext(ctx, method, url, nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if DebugMode {
-		fmt.Println(string(bytes))
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func unauthenticatedPostAndDecode(ctx context.Context, client *http.Client, url string, data interface{}, dest Detailable, header http.Header) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
 	}
-	res, err := http.Post(url, "application/json", strings.NewReader(string(bytes)))
+	req, err := newRequest(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return err
 	}
-	if res.StatusCode != 200 {
-		fmt.Printf("Got status code %v from URL %v\n", res.StatusCode, url)
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
 	}
-
 	defer res.Body.Close()
 
-	return unmarshalJSON(res.Body, dest)
+	return decodeResponse(res, dest)
 }
 
-// unmarshalJSON wraps json.Unmarshal
-// It will log the response if DebugMode is true
-func unmarshalJSON(r io.Reader, dest Detailable) error {
-	body, err := ioutil.ReadAll(r)
+// decodeResponse decodes the JSON body of res into dest. If the response has
+// an error status or dest reports an error detail, an *APIError is returned.
+// dest is still decoded on error where possible, so callers may inspect it.
+func decodeResponse(res *http.Response, dest Detailable) error {
+	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return err
 	}
-	if DebugMode {
-		fmt.Println(string(body))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		json.Unmarshal(body, dest)
+		return newAPIError(res, body)
+	}
+
+	if len(body) == 0 {
+		return nil
 	}
-	err = json.Unmarshal(body, &dest)
+	err = json.Unmarshal(body, dest)
 	if err != nil {
 		return err
 	}
-	deets := dest.Details()
-	if deets != "" {
-		return fmt.Errorf("response contained error %v", deets)
+	if dest.Details() != "" {
+		return newAPIError(res, body)
 	}
 	return nil
 }
@@ -1,19 +1,39 @@
 package robinhood
 
 import (
-	"fmt"
-	"io/ioutil"
-	"os"
-	"path"
+	"context"
+	"net/http"
 	"time"
 )
 
+// DebugMode makes Clients and Creds that have no Logger log every request,
+// redacted, to stdout.
+//
+// Deprecated: use WithLogger or Creds.Logger.
 var DebugMode bool
 
 type TokenGetter interface {
 	GetToken() (string, error)
 }
 
+// A ContextTokenGetter is a TokenGetter whose token retrieval can be
+// cancelled.
+type ContextTokenGetter interface {
+	TokenGetter
+	GetTokenContext(ctx context.Context) (string, error)
+}
+
+// getToken retrieves a token from t, honoring ctx if t supports it.
+func getToken(ctx context.Context, t TokenGetter) (string, error) {
+	if ct, ok := t.(ContextTokenGetter); ok {
+		return ct.GetTokenContext(ctx)
+	}
+	return t.GetToken()
+}
+
+// defaultClientID is the OAuth client id of the Robinhood website.
+const defaultClientID = "c82SH0WZOsabOXGP2sxqcj34FxkvfnWRZBKlBjFS"
+
 type Creds struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -23,13 +43,40 @@ type Creds struct {
 	Scope     string `json:"scope"`
 	ClientId  string `json:"client_id"`
 	GrantType string `json:"grant_type"`
+	// sms or email; the channel Robinhood should send verification codes
+	// through when it challenges a login
+	ChallengeType ChallengeType `json:"challenge_type,omitempty"`
+	// Identifies this device to Robinhood, which challenges logins from
+	// devices it doesn't know. Login generates one if it is empty; reuse it
+	// (CredsCacher stores it with the token) to avoid repeated challenges.
+	DeviceToken string `json:"device_token,omitempty"`
+
+	// MFAProvider, if set, is asked for verification codes when logging in
+	// requires them.
+	MFAProvider MFAProvider `json:"-"`
+	// BaseURL, if set, is used when logging in in place of the base URL of
+	// the Client logging in, or of the live Robinhood API.
+	BaseURL string `json:"-"`
+	// Logger, if set, receives the login request with the password and MFA
+	// code redacted.
+	Logger Logger `json:"-"`
+	// HTTPClient, if set, is used to make login requests. Otherwise logins
+	// made by a Client use its transport, timeout, headers and rate limits.
+	HTTPClient *http.Client `json:"-"`
 }
 
 type LoginResponse struct {
-	Token       string `json:"access_token"`
+	Token        string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// Lifetime of the token in seconds
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
 	MFAType     string `json:"mfa_type"`
 	MFARequired bool   `json:"mfa_required"`
-	Detail      string `json:"detail"`
+	// Set when the login was blocked pending verification
+	Challenge *MFAChallenge `json:"challenge"`
+	Detail    string        `json:"detail"`
 }
 
 func (resp *LoginResponse) Details() string {
@@ -49,76 +96,62 @@ func NewCredsWithMFA(username, password, mfa string) *Creds {
 		ExpiresIn: int((72 * time.Hour) / time.Second),
 		// These are the values that the Robinhood Website uses:
 		Scope:     "internal",
-		ClientId:  "c82SH0WZOsabOXGP2sxqcj34FxkvfnWRZBKlBjFS",
+		ClientId:  defaultClientID,
 		GrantType: "password",
 	}
 }
 
-func (c *Creds) GetToken() (string, error) {
-	var resp LoginResponse
-	err := unauthenticatedPostAndDecode(epLogin, c, &resp)
-	if err != nil {
-		return "", err
+// url resolves the endpoint ep against the Creds' base URL, or else that of
+// the Client logging in under ctx.
+func (c *Creds) url(ctx context.Context, ep string) string {
+	if c.BaseURL != "" {
+		return withSlash(c.BaseURL) + ep
 	}
-	if resp.MFARequired {
-		return "", fmt.Errorf("this account requires two factor. Two factor type: %v", resp.MFAType)
+	if cl := loggingInClient(ctx); cl != nil {
+		return cl.url(ep)
 	}
-	return resp.Token, nil
+	return epBase + ep
 }
 
-// A CredsCacher takes user credentials and a file path. The token obtained
-// from the RobinHood API will be cached at the file path, and a new token will
-// not be obtained.
-type CredsCacher struct {
-	Creds TokenGetter
-	Path  string
+// httpClient returns the http.Client used to log in under ctx.
+func (c *Creds) httpClient(ctx context.Context) *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	if cl := loggingInClient(ctx); cl != nil {
+		return cl.loginClient(c.Logger)
+	}
+	return &http.Client{Transport: newLoggingTransport(c.Logger, http.DefaultTransport)}
 }
 
-// GetToken implements TokenGetter. It may fail if an error is encountered
-// checking the file path provided, or if the underlying creds return an error
-// when retrieving their token.
-func (c *CredsCacher) GetToken() (string, error) {
-	mustLogin := false
-
-	err := os.MkdirAll(path.Dir(c.Path), 0750)
-	if err != nil {
-		return "", fmt.Errorf("error creating path for token: %s", err)
-	}
+type loggingInClientCtx struct{}
 
-	_, err = os.Stat(c.Path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			mustLogin = true
-		} else {
-			return "", err
-		}
-	}
+// withLoggingInClient returns a context under which Creds log in with cl's
+// configuration, where they have none of their own. A Client logs in under
+// it rather than configuring the Creds, which may be shared with other
+// Clients.
+func withLoggingInClient(ctx context.Context, cl *Client) context.Context {
+	return context.WithValue(ctx, loggingInClientCtx{}, cl)
+}
 
-	if !mustLogin {
-		bs, err := ioutil.ReadFile(c.Path)
-		bsstr := string(bs)
-		if err != nil || bsstr != "" {
-			return bsstr, err
-		}
-	}
+// loggingInClient returns the Client logging in under ctx, if any.
+func loggingInClient(ctx context.Context) *Client {
+	cl, _ := ctx.Value(loggingInClientCtx{}).(*Client)
+	return cl
+}
 
-	f, err := os.OpenFile(c.Path, os.O_CREATE|os.O_RDWR, 0640)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
+// GetToken implements TokenGetter.
+func (c *Creds) GetToken() (string, error) {
+	return c.GetTokenContext(context.Background())
+}
 
-	tok, err := c.Creds.GetToken()
+// GetTokenContext implements ContextTokenGetter.
+func (c *Creds) GetTokenContext(ctx context.Context) (string, error) {
+	tok, err := c.Login(ctx)
 	if err != nil {
 		return "", err
 	}
-
-	if tok == "" {
-		return "", fmt.Errorf("Empty token")
-	}
-
-	_, err = f.Write([]byte(tok))
-	return tok, err
+	return tok.AccessToken, nil
 }
 
 type Token string
@@ -0,0 +1,365 @@
+package robinhood
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// A CredentialSource supplies login credentials when they are needed, so
+// that passwords don't have to be written into programs.
+type CredentialSource interface {
+	Credentials(ctx context.Context) (*Creds, error)
+}
+
+// sourceFields are the credentials a CredentialSource can supply. They are
+// also the JSON form read by FileSource and CommandSource.
+type sourceFields struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	MFA         string `json:"mfa_code"`
+	TOTPSecret  string `json:"totp_secret"`
+	DeviceToken string `json:"device_token"`
+}
+
+// creds returns Creds for the fields, using TOTPSecret, if set, to generate
+// MFA codes.
+func (f *sourceFields) creds(source string) (*Creds, error) {
+	if f.Username == "" || f.Password == "" {
+		return nil, fmt.Errorf("robinhood: %s has no username or password", source)
+	}
+	c := NewCredsWithMFA(f.Username, f.Password, f.MFA)
+	if f.TOTPSecret != "" {
+		c.MFAProvider = &TOTPProvider{Secret: f.TOTPSecret}
+	}
+	c.DeviceToken = f.DeviceToken
+	return c, nil
+}
+
+// An EnvSource reads credentials from environment variables named after
+// Prefix: PREFIX_USERNAME and PREFIX_PASSWORD, and optionally PREFIX_MFA,
+// PREFIX_TOTP_SECRET and PREFIX_DEVICE_TOKEN.
+type EnvSource struct {
+	// Prefix defaults to "ROBINHOOD".
+	Prefix string
+}
+
+// Credentials implements CredentialSource.
+func (s *EnvSource) Credentials(ctx context.Context) (*Creds, error) {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "ROBINHOOD"
+	}
+	f := sourceFields{
+		Username:    os.Getenv(prefix + "_USERNAME"),
+		Password:    os.Getenv(prefix + "_PASSWORD"),
+		MFA:         os.Getenv(prefix + "_MFA"),
+		TOTPSecret:  os.Getenv(prefix + "_TOTP_SECRET"),
+		DeviceToken: os.Getenv(prefix + "_DEVICE_TOKEN"),
+	}
+	return f.creds("environment " + prefix + "_*")
+}
+
+// A FileSource reads credentials from a file, which is either a JSON object
+// with username, password and optionally mfa_code, totp_secret and
+// device_token fields, or in netrc format:
+//
+//	machine api.robinhood.com login USERNAME password PASSWORD
+//
+// The file should be readable only by its owner.
+type FileSource struct {
+	Path string
+	// Machine is the netrc machine to use; it defaults to
+	// "api.robinhood.com". A "default" entry is used if there is no match.
+	Machine string
+}
+
+// Credentials implements CredentialSource.
+func (s *FileSource) Credentials(ctx context.Context) (*Creds, error) {
+	bs, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f sourceFields
+	if trimmed := bytes.TrimSpace(bs); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &f); err != nil {
+			return nil, fmt.Errorf("robinhood: reading %s: %s", s.Path, err)
+		}
+	} else {
+		machine := s.Machine
+		if machine == "" {
+			machine = "api.robinhood.com"
+		}
+		f = parseNetrc(string(bs), machine)
+	}
+	return f.creds(s.Path)
+}
+
+// parseNetrc returns the login and password of machine in a netrc file, or
+// of its default entry.
+func parseNetrc(data, machine string) sourceFields {
+	// Drop macro bodies, which run from the line after macdef to the next
+	// empty line, so that their words aren't taken for tokens.
+	var kept []string
+	inMacro := false
+	for _, line := range strings.Split(data, "\n") {
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		kept = append(kept, line)
+		for _, f := range strings.Fields(line) {
+			if f == "macdef" {
+				inMacro = true
+			}
+		}
+	}
+
+	var found, fallback *sourceFields
+	var cur *sourceFields
+	fields := strings.Fields(strings.Join(kept, "\n"))
+	for i := 0; i < len(fields); i++ {
+		next := func() string {
+			if i+1 < len(fields) {
+				i++
+				return fields[i]
+			}
+			return ""
+		}
+		switch fields[i] {
+		case "machine":
+			cur = &sourceFields{}
+			if next() == machine && found == nil {
+				found = cur
+			}
+		case "default":
+			cur = &sourceFields{}
+			if fallback == nil {
+				fallback = cur
+			}
+		case "login":
+			if v := next(); cur != nil {
+				cur.Username = v
+			}
+		case "password":
+			if v := next(); cur != nil {
+				cur.Password = v
+			}
+		case "account":
+			next()
+		case "macdef":
+			// A macro ends the entry it follows.
+			next()
+			cur = nil
+		}
+	}
+	switch {
+	case found != nil:
+		return *found
+	case fallback != nil:
+		return *fallback
+	}
+	return sourceFields{}
+}
+
+// A CommandSource runs a command, such as a password manager, and reads
+// credentials from its output. The output is either a JSON object as read by
+// FileSource, or in the format of pass(1): the password on the first line,
+// optionally followed by "key: value" lines where the keys username (or
+// login), totp_secret, mfa_code and device_token are recognised.
+//
+// For example, with pass:
+//
+//	&CommandSource{Path: "pass", Args: []string{"show", "robinhood"}}
+type CommandSource struct {
+	Path string
+	Args []string
+	// Username is used if the output doesn't include one.
+	Username string
+}
+
+// Credentials implements CredentialSource.
+func (s *CommandSource) Credentials(ctx context.Context) (*Creds, error) {
+	out, err := exec.CommandContext(ctx, s.Path, s.Args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("robinhood: running %s: %s: %s", s.Path, err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("robinhood: running %s: %s", s.Path, err)
+	}
+
+	var f sourceFields
+	if trimmed := bytes.TrimSpace(out); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &f); err != nil {
+			return nil, fmt.Errorf("robinhood: reading output of %s: %s", s.Path, err)
+		}
+	} else {
+		f = parsePassOutput(out)
+	}
+	if f.Username == "" {
+		f.Username = s.Username
+	}
+	return f.creds("output of " + s.Path)
+}
+
+// parsePassOutput parses credentials in the format of pass(1).
+func parsePassOutput(out []byte) sourceFields {
+	var f sourceFields
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	if sc.Scan() {
+		f.Password = strings.TrimRight(sc.Text(), "\r")
+	}
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "username", "login", "user":
+			f.Username = v
+		case "totp_secret", "totp":
+			f.TOTPSecret = v
+		case "mfa_code", "mfa":
+			f.MFA = v
+		case "device_token":
+			f.DeviceToken = v
+		}
+	}
+	return f
+}
+
+// SourceCreds are Creds read from a CredentialSource when a login needs them,
+// rather than when the program starts. Wrapped in a CredsCacher, the source
+// is only consulted when the cached token can't be used or refreshed, e.g.
+//
+//	c := &CredsCacher{
+//		Creds: &SourceCreds{Source: &CommandSource{Path: "pass", Args: []string{"show", "robinhood"}}},
+//		Path:  "/home/me/.config/robinhood/token",
+//	}
+//
+// The source is read again for each login, so a changed password is picked
+// up without restarting. The device token of the first login is kept.
+type SourceCreds struct {
+	Source CredentialSource
+
+	// MFAProvider, BaseURL and Logger are set on the Creds from Source
+	// unless it sets them itself.
+	MFAProvider MFAProvider
+	BaseURL     string
+	Logger      Logger
+
+	mu     sync.Mutex
+	creds  *Creds
+	device string
+}
+
+// GetToken implements TokenGetter.
+func (s *SourceCreds) GetToken() (string, error) {
+	return s.GetTokenContext(context.Background())
+}
+
+// GetTokenContext implements ContextTokenGetter.
+func (s *SourceCreds) GetTokenContext(ctx context.Context) (string, error) {
+	tok, err := s.Login(ctx)
+	if err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
+
+// Login implements TokenRefresher by reading the credentials from Source and
+// logging in with them.
+func (s *SourceCreds) Login(ctx context.Context) (*OAuthToken, error) {
+	c, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := c.Login(ctx)
+
+	s.mu.Lock()
+	s.device = c.DeviceToken
+	s.mu.Unlock()
+	return tok, err
+}
+
+// Refresh implements TokenRefresher. Refreshing a token doesn't need a
+// password, so Source is not read.
+func (s *SourceCreds) Refresh(ctx context.Context, tok *OAuthToken) (*OAuthToken, error) {
+	s.mu.Lock()
+	c := s.creds
+	if c == nil {
+		c = s.configure(NewCreds("", ""))
+	}
+	s.mu.Unlock()
+	return c.Refresh(ctx, tok)
+}
+
+// load reads the Creds from Source.
+func (s *SourceCreds) load(ctx context.Context) (*Creds, error) {
+	c, err := s.Source.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configure(c)
+	if c.DeviceToken == "" {
+		c.DeviceToken = s.device
+	}
+	s.creds = c
+	return c, nil
+}
+
+// configure sets the SourceCreds' MFAProvider, BaseURL and Logger on c where
+// c doesn't set its own, and returns c. s.mu must be held.
+func (s *SourceCreds) configure(c *Creds) *Creds {
+	if c.MFAProvider == nil {
+		c.MFAProvider = s.MFAProvider
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = s.BaseURL
+	}
+	if c.Logger == nil {
+		c.Logger = s.Logger
+	}
+	return c
+}
+
+// username returns the username most recently read from Source, if any.
+func (s *SourceCreds) username() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.creds == nil {
+		return ""
+	}
+	return s.creds.Username
+}
+
+// deviceToken returns the device token to log in with, adopting tkn if there
+// is none yet and generating one if tkn is empty too.
+func (s *SourceCreds) deviceToken(tkn string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.device == "" {
+		s.device = tkn
+	}
+	if s.device == "" {
+		var err error
+		if s.device, err = NewDeviceToken(); err != nil {
+			return "", err
+		}
+	}
+	return s.device, nil
+}
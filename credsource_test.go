@@ -0,0 +1,222 @@
+package robinhood_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// wantCreds describes the Creds a CredentialSource should produce. An empty
+// wantErr means none is expected.
+type wantCreds struct {
+	username, password, mfa, device string
+	totp                            bool
+	wantErr                         string
+}
+
+func checkCreds(t *testing.T, name string, c *robinhood.Creds, err error, want wantCreds) {
+	t.Helper()
+	if want.wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), want.wantErr) {
+			t.Errorf("%s: got %v, want an error containing %q", name, err, want.wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	if c.Username != want.username || c.Password != want.password || c.MFA != want.mfa || c.DeviceToken != want.device {
+		t.Errorf("%s: got %s/%s mfa %q device %q, want %s/%s mfa %q device %q", name,
+			c.Username, c.Password, c.MFA, c.DeviceToken, want.username, want.password, want.mfa, want.device)
+	}
+	p, ok := c.MFAProvider.(*robinhood.TOTPProvider)
+	if ok != want.totp || (ok && p.Secret != testTOTPSecret) {
+		t.Errorf("%s: got MFAProvider %#v, want TOTP %v", name, c.MFAProvider, want.totp)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	tests := []struct {
+		name, data, machine string
+		want                wantCreds
+	}{
+		{
+			name: "netrc",
+			data: "machine api.robinhood.com login bob password hunter2\n",
+			want: wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name: "netrc multiple machines",
+			data: "machine example.com login alice password secret\n" +
+				"machine api.robinhood.com\n\tlogin bob\n\tpassword hunter2\n" +
+				"machine other.com login carol password pw\n",
+			want: wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name:    "netrc other machine",
+			data:    "machine example.com login alice password secret\nmachine api.robinhood.com login bob password hunter2\n",
+			machine: "example.com",
+			want:    wantCreds{username: "alice", password: "secret"},
+		},
+		{
+			name: "netrc default",
+			data: "machine example.com login alice password secret\ndefault login dave password fallback\n",
+			want: wantCreds{username: "dave", password: "fallback"},
+		},
+		{
+			name: "netrc machine before default",
+			data: "default login dave password fallback\nmachine api.robinhood.com login bob password hunter2 account x\n",
+			want: wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name: "netrc macdef",
+			data: "machine api.robinhood.com login bob password hunter2\n" +
+				"macdef init\nlogin mallory\npassword evil\n\n" +
+				"machine example.com login alice password secret\n",
+			want: wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name: "netrc words after macdef",
+			data: "macdef init\ncd /tmp\n\nlogin mallory password evil\nmachine api.robinhood.com login bob password hunter2\n",
+			want: wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name: "netrc no password",
+			data: "machine api.robinhood.com login bob\n",
+			want: wantCreds{wantErr: "no username or password"},
+		},
+		{
+			name: "netrc no match",
+			data: "machine example.com login alice password secret\n",
+			want: wantCreds{wantErr: "no username or password"},
+		},
+		{
+			name: "json",
+			data: `{"username": "bob", "password": "hunter2", "totp_secret": "` + testTOTPSecret + `", "device_token": "dev-1"}`,
+			want: wantCreds{username: "bob", password: "hunter2", device: "dev-1", totp: true},
+		},
+		{
+			name: "json mfa code",
+			data: "\n  {\"username\": \"bob\", \"password\": \"hunter2\", \"mfa_code\": \"123456\"}\n",
+			want: wantCreds{username: "bob", password: "hunter2", mfa: "123456"},
+		},
+		{
+			name: "json no username",
+			data: `{"password": "hunter2"}`,
+			want: wantCreds{wantErr: "no username or password"},
+		},
+		{
+			name: "json invalid",
+			data: `{"username": "bob",`,
+			want: wantCreds{wantErr: "reading"},
+		},
+	}
+	dir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(dir, strings.Repeat("x", i+1))
+		if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := (&robinhood.FileSource{Path: path, Machine: tt.machine}).Credentials(context.Background())
+		checkCreds(t, tt.name, c, err, tt.want)
+	}
+
+	if _, err := (&robinhood.FileSource{Path: filepath.Join(dir, "missing")}).Credentials(context.Background()); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v, want not exist", err)
+	}
+}
+
+func TestCommandSource(t *testing.T) {
+	tests := []struct {
+		name, output, username string
+		want                   wantCreds
+	}{
+		{
+			name:   "pass",
+			output: "hunter2\nlogin: bob\ntotp: " + testTOTPSecret + "\n",
+			want:   wantCreds{username: "bob", password: "hunter2", totp: true},
+		},
+		{
+			name:   "pass all keys",
+			output: "hunter2\nURL: https://robinhood.com\nUsername: bob\nmfa_code: 123456\ndevice_token: dev-1\nnotes without a colon\n",
+			want:   wantCreds{username: "bob", password: "hunter2", mfa: "123456", device: "dev-1"},
+		},
+		{
+			name:   "pass CRLF",
+			output: "hunter2\r\nuser: bob\r\n",
+			want:   wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name:     "pass password only",
+			output:   "p: ss word\n",
+			username: "bob",
+			want:     wantCreds{username: "bob", password: "p: ss word"},
+		},
+		{
+			name:     "pass username from output",
+			output:   "hunter2\nlogin: bob\n",
+			username: "other",
+			want:     wantCreds{username: "bob", password: "hunter2"},
+		},
+		{
+			name:   "pass no username",
+			output: "hunter2\n",
+			want:   wantCreds{wantErr: "no username or password"},
+		},
+		{
+			name:   "empty",
+			output: "",
+			want:   wantCreds{wantErr: "no username or password"},
+		},
+		{
+			name:   "json",
+			output: `{"username": "bob", "password": "hunter2", "totp_secret": "` + testTOTPSecret + `"}`,
+			want:   wantCreds{username: "bob", password: "hunter2", totp: true},
+		},
+		{
+			name:   "json no password",
+			output: `{"username": "bob"}`,
+			want:   wantCreds{wantErr: "no username or password"},
+		},
+	}
+	dir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(dir, strings.Repeat("x", i+1))
+		if err := os.WriteFile(path, []byte(tt.output), 0600); err != nil {
+			t.Fatal(err)
+		}
+		s := &robinhood.CommandSource{Path: "cat", Args: []string{path}, Username: tt.username}
+		c, err := s.Credentials(context.Background())
+		checkCreds(t, tt.name, c, err, tt.want)
+	}
+
+	s := &robinhood.CommandSource{Path: "cat", Args: []string{filepath.Join(dir, "missing")}}
+	if _, err := s.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("failing command: got %v, want its stderr", err)
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv("ROBINHOOD_USERNAME", "bob")
+	t.Setenv("ROBINHOOD_PASSWORD", "hunter2")
+	t.Setenv("ROBINHOOD_DEVICE_TOKEN", "dev-1")
+	c, err := (&robinhood.EnvSource{}).Credentials(context.Background())
+	checkCreds(t, "default prefix", c, err, wantCreds{username: "bob", password: "hunter2", device: "dev-1"})
+
+	t.Setenv("RHTEST_USERNAME", "alice")
+	t.Setenv("RHTEST_PASSWORD", "secret")
+	t.Setenv("RHTEST_TOTP_SECRET", testTOTPSecret)
+	c, err = (&robinhood.EnvSource{Prefix: "RHTEST"}).Credentials(context.Background())
+	checkCreds(t, "prefix", c, err, wantCreds{username: "alice", password: "secret", totp: true})
+
+	t.Setenv("RHTEST_PASSWORD", "")
+	c, err = (&robinhood.EnvSource{Prefix: "RHTEST"}).Credentials(context.Background())
+	checkCreds(t, "no password", c, err, wantCreds{wantErr: "no username or password"})
+}
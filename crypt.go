@@ -0,0 +1,114 @@
+package robinhood
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedPrefix marks a cache file encrypted by a CredsCacher. Files
+// without it are plaintext tokens written by earlier versions.
+var encryptedPrefix = []byte("rhenc1:")
+
+// scrypt parameters for deriving cache keys from passphrases.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	saltSize     = 16
+	cacheKeySize = 32
+)
+
+// errNoCacheKey is returned when reading an encrypted cache without a key.
+var errNoCacheKey = errors.New("robinhood: token cache is encrypted but no Key or Passphrase is set")
+
+// encrypted returns whether c is configured to encrypt its cache.
+func (c *CredsCacher) encrypted() bool {
+	return len(c.Key) > 0 || c.Passphrase != ""
+}
+
+// cacheKey returns the AES key for the given salt: Key if set, or else one
+// derived from Passphrase.
+func (c *CredsCacher) cacheKey(salt []byte) ([]byte, error) {
+	if len(c.Key) > 0 {
+		return c.Key, nil
+	}
+	return scrypt.Key([]byte(c.Passphrase), salt, scryptN, scryptR, scryptP, cacheKeySize)
+}
+
+// seal encrypts plaintext with AES-GCM, returning it prefixed with
+// encryptedPrefix and base64 encoded along with its salt and nonce.
+func (c *CredsCacher) seal(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := c.gcm(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append(salt, nonce...)
+	sealed = gcm.Seal(sealed, nonce, plaintext, nil)
+
+	out := make([]byte, len(encryptedPrefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(out, encryptedPrefix)
+	base64.StdEncoding.Encode(out[len(encryptedPrefix):], sealed)
+	return out, nil
+}
+
+// open decrypts data written by seal. Data without encryptedPrefix is
+// returned as is, with plain set to true.
+func (c *CredsCacher) open(data []byte) (plaintext []byte, plain bool, err error) {
+	if !bytes.HasPrefix(data, encryptedPrefix) {
+		return data, true, nil
+	}
+	if !c.encrypted() {
+		return nil, false, errNoCacheKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(encryptedPrefix):])))
+	if err != nil {
+		return nil, false, fmt.Errorf("robinhood: corrupt token cache: %s", err)
+	}
+	if len(sealed) < saltSize {
+		return nil, false, errors.New("robinhood: corrupt token cache")
+	}
+	gcm, err := c.gcm(sealed[:saltSize])
+	if err != nil {
+		return nil, false, err
+	}
+	sealed = sealed[saltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, false, errors.New("robinhood: corrupt token cache")
+	}
+
+	plaintext, err = gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, false, errors.New("robinhood: cannot decrypt token cache: wrong key or passphrase")
+	}
+	return plaintext, false, nil
+}
+
+// gcm returns the AES-GCM cipher for the given salt.
+func (c *CredsCacher) gcm(salt []byte) (cipher.AEAD, error) {
+	key, err := c.cacheKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
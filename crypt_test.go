@@ -0,0 +1,106 @@
+package robinhood_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+)
+
+func TestCredsCacherMigratesPlaintext(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for name, plaintext := range map[string]string{
+		"json":         `{"access_token":"plain-token","refresh_token":"plain-refresh","expiry":"` + expiry + `"}`,
+		"access token": "plain-token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "token")
+			if err := os.WriteFile(path, []byte(plaintext), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			creds := &countingCreds{}
+			c := &robinhood.CredsCacher{Creds: creds, Path: path, Passphrase: "correct horse"}
+			tok, err := c.Login(context.Background())
+			if err != nil || tok.AccessToken != "plain-token" {
+				t.Fatalf("Login: got %v, %v, want the cached plain-token", tok, err)
+			}
+			if creds.logins != 0 {
+				t.Errorf("%d logins, want the cached token used", creds.logins)
+			}
+
+			bs, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(bs, []byte("rhenc1:")) || bytes.Contains(bs, []byte("plain-")) {
+				t.Errorf("cache not rewritten encrypted: %s", bs)
+			}
+
+			// The encrypted cache reads back.
+			c = &robinhood.CredsCacher{Creds: creds, Path: path, Passphrase: "correct horse"}
+			if tok, err := c.Login(context.Background()); err != nil || tok.AccessToken != "plain-token" {
+				t.Errorf("Login from the encrypted cache: got %v, %v, want plain-token", tok, err)
+			}
+		})
+	}
+}
+
+func TestCredsCacherWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	creds := &countingCreds{}
+	c := &robinhood.CredsCacher{Creds: creds, Path: path, Passphrase: "correct horse"}
+	if _, err := c.Login(context.Background()); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]*robinhood.CredsCacher{
+		"wrong passphrase": {Creds: creds, Path: path, Passphrase: "battery staple"},
+		"wrong key":        {Creds: creds, Path: path, Key: bytes.Repeat([]byte{1}, 32)},
+		"no passphrase":    {Creds: creds, Path: path},
+	} {
+		_, err := c.Login(context.Background())
+		if err == nil {
+			t.Errorf("%s: Login succeeded", name)
+			continue
+		}
+		if want := "passphrase"; !strings.Contains(strings.ToLower(err.Error()), want) {
+			t.Errorf("%s: got %v, want an error mentioning the %s", name, err, want)
+		}
+	}
+	if creds.logins != 1 {
+		t.Errorf("%d logins, want the unreadable cache to fail rather than log in", creds.logins)
+	}
+	if after, err := os.ReadFile(path); err != nil || !bytes.Equal(before, after) {
+		t.Errorf("unreadable cache was modified")
+	}
+}
+
+func TestCredsCacherKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	key := bytes.Repeat([]byte{7}, 16)
+	creds := &countingCreds{}
+	c := &robinhood.CredsCacher{Creds: creds, Path: path, Key: key}
+	first, err := c.Login(context.Background())
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	c = &robinhood.CredsCacher{Creds: creds, Path: path, Key: key}
+	if tok, err := c.Login(context.Background()); err != nil || tok.AccessToken != first.AccessToken {
+		t.Errorf("Login with the same key: got %v, %v, want %s", tok, err, first.AccessToken)
+	}
+
+	c = &robinhood.CredsCacher{Creds: creds, Path: filepath.Join(t.TempDir(), "token"), Key: []byte("short")}
+	if _, err := c.Login(context.Background()); err == nil {
+		t.Error("Login with an invalid key length succeeded")
+	}
+}
@@ -0,0 +1,217 @@
+package robinhood
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Pattern day trader rule parameters. A margin account with less than
+// PatternDayTraderEquity may make at most MaxDayTrades day trades in any
+// DayTradeWindow trading days.
+const (
+	MaxDayTrades   = 3
+	DayTradeWindow = 5
+)
+
+// PatternDayTraderEquity is the equity below which a margin account is
+// limited to MaxDayTrades day trades.
+var PatternDayTraderEquity = MustParseDecimal("25000")
+
+// UnlimitedDayTrades is the number of remaining day trades reported for
+// accounts the pattern day trader rule doesn't limit.
+const UnlimitedDayTrades = -1
+
+// ErrDayTradeLimit is returned by AccountClient.SendOrder, when it guards
+// day trades, for an order that would exceed the account's day trade limit.
+var ErrDayTradeLimit = errors.New("robinhood: order would exceed the pattern day trade limit")
+
+// A DayTrade is the purchase and sale of an instrument on the same trading
+// day.
+type DayTrade struct {
+	// Date is the trading day, at midnight in New York.
+	Date       time.Time
+	Instrument string
+	// Quantity is the number of shares bought and sold that day.
+	Quantity Decimal
+}
+
+// tradingDay returns midnight in New York of the day t falls on.
+func tradingDay(t time.Time) time.Time {
+	t = t.In(nyLoc())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dayTradeWindowStart returns the first of the DayTradeWindow trading days
+// ending on the day of now. Weekends are skipped, but market holidays are
+// not, so the window may be a trading day shorter than the rule's.
+func dayTradeWindowStart(now time.Time) time.Time {
+	day := tradingDay(now)
+	for n := 1; n < DayTradeWindow; {
+		day = day.AddDate(0, 0, -1)
+		if isWeekday(day) {
+			n++
+		}
+	}
+	return day
+}
+
+// fill is an execution of an order.
+type fill struct {
+	Execution
+	instrument string
+	side       Side
+	// order is the index of the filled order.
+	order int
+}
+
+// CountDayTrades reconstructs the day trades made by the executions of
+// orders on or after since. Each sell order that sells shares bought earlier
+// the same day is one day trade, however many executions filled it; selling
+// shares held overnight is not.
+func CountDayTrades(orders []Order, since time.Time) []DayTrade {
+	type key struct {
+		day        time.Time
+		instrument string
+	}
+	fills := map[key][]fill{}
+	for i, o := range orders {
+		for _, e := range o.Executions {
+			if e.Timestamp.Before(since) {
+				continue
+			}
+			k := key{tradingDay(e.Timestamp), o.Instrument}
+			fills[k] = append(fills[k], fill{e, o.Instrument, o.Side, i})
+		}
+	}
+
+	var trades []DayTrade
+	for k, fs := range fills {
+		sort.SliceStable(fs, func(i, j int) bool { return fs[i].Timestamp.Before(fs[j].Timestamp) })
+		var open Decimal
+		// sells maps sell orders to their day trade in trades.
+		sells := map[int]int{}
+		for _, f := range fs {
+			if f.side == Side_Buy {
+				open = open.Add(f.Quantity)
+				continue
+			}
+			if open.Sign() <= 0 {
+				continue
+			}
+			q := f.Quantity
+			if open.Cmp(q) < 0 {
+				q = open
+			}
+			open = open.Sub(q)
+			if t, ok := sells[f.order]; ok {
+				trades[t].Quantity = trades[t].Quantity.Add(q)
+				continue
+			}
+			sells[f.order] = len(trades)
+			trades = append(trades, DayTrade{Date: k.day, Instrument: k.instrument, Quantity: q})
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].Date.Equal(trades[j].Date) {
+			return trades[i].Date.Before(trades[j].Date)
+		}
+		return trades[i].Instrument < trades[j].Instrument
+	})
+	return trades
+}
+
+// GetDayTrades returns the day trades made in the account over the last
+// DayTradeWindow trading days, including today.
+func (c *AccountClient) GetDayTrades() ([]DayTrade, error) {
+	return c.GetDayTradesContext(context.Background())
+}
+
+// GetDayTradesContext is like GetDayTrades but every request is bound to ctx.
+func (c *AccountClient) GetDayTradesContext(ctx context.Context) ([]DayTrade, error) {
+	since := dayTradeWindowStart(time.Now())
+	// Orders executed in the window were last updated in it too.
+	orders, err := c.orders(ctx, url.Values{"updated_at[gte]": {since.UTC().Format(time.RFC3339)}})
+	if err != nil {
+		return nil, err
+	}
+	return CountDayTrades(orders, since), nil
+}
+
+// RemainingDayTrades returns how many more day trades the account can make
+// in the current window without being flagged as a pattern day trader, or
+// UnlimitedDayTrades if it isn't limited: it is a cash account, or it has at
+// least PatternDayTraderEquity. An account already flagged with less than
+// PatternDayTraderEquity has none.
+func (c *AccountClient) RemainingDayTrades() (int, error) {
+	return c.RemainingDayTradesContext(context.Background())
+}
+
+// RemainingDayTradesContext is like RemainingDayTrades but every request is
+// bound to ctx.
+func (c *AccountClient) RemainingDayTradesContext(ctx context.Context) (int, error) {
+	a, err := c.Client.GetAccountContext(ctx, c.Account.AccountNumber)
+	if err != nil {
+		return 0, err
+	}
+	if a.Type == "cash" {
+		return UnlimitedDayTrades, nil
+	}
+	p, err := c.Client.GetPortfolioContext(ctx, a)
+	if err != nil {
+		return 0, err
+	}
+	if p.Equity.Cmp(PatternDayTraderEquity) >= 0 {
+		return UnlimitedDayTrades, nil
+	}
+	if a.MarginBalances.MarkedPatternDayTraderDate != "" {
+		return 0, nil
+	}
+
+	trades, err := c.GetDayTradesContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(trades) >= MaxDayTrades {
+		return 0, nil
+	}
+	return MaxDayTrades - len(trades), nil
+}
+
+// checkDayTrade returns ErrDayTradeLimit if request would be a day trade
+// and the account has none remaining.
+func (c *AccountClient) checkDayTrade(ctx context.Context, request *OrderRequest) error {
+	if request.Side != Side_Sell || request.OverrideDayTradeChecks {
+		return nil
+	}
+
+	// The sale is a day trade if shares of the instrument were bought today
+	// and not yet sold.
+	now := time.Now()
+	since := tradingDay(now)
+	params := url.Values{"instrument": {request.Instrument}, "updated_at[gte]": {since.UTC().Format(time.RFC3339)}}
+	orders, err := c.orders(ctx, params)
+	if err != nil {
+		return err
+	}
+	sell := Order{
+		Instrument: request.Instrument,
+		Side:       Side_Sell,
+		Executions: []Execution{{Quantity: DecimalFromInt(int64(request.Quantity)), Timestamp: now}},
+	}
+	if len(CountDayTrades(append(orders, sell), since)) == len(CountDayTrades(orders, since)) {
+		return nil
+	}
+
+	remaining, err := c.RemainingDayTradesContext(ctx)
+	if err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return fmt.Errorf("%w: selling %s would be a day trade", ErrDayTradeLimit, request.Symbol)
+	}
+	return nil
+}
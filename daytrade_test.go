@@ -0,0 +1,131 @@
+package robinhood_test
+
+import (
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+)
+
+func TestCountDayTrades(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hour, min int) time.Time { return time.Date(2024, time.March, day, hour, min, 0, 0, ny) }
+	exec := func(ts time.Time, q string) robinhood.Execution {
+		return robinhood.Execution{Quantity: robinhood.MustParseDecimal(q), Timestamp: ts}
+	}
+	order := func(instrument string, side robinhood.Side, execs ...robinhood.Execution) robinhood.Order {
+		return robinhood.Order{Instrument: instrument, Side: side, Executions: execs}
+	}
+	const a, b = "inst/a", "inst/b"
+	since := at(4, 0, 0)
+
+	type trade struct {
+		day        int
+		instrument string
+		quantity   string
+	}
+	tests := []struct {
+		name   string
+		orders []robinhood.Order
+		want   []trade
+	}{
+		{
+			name: "round trip",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "10")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "10")),
+			},
+			want: []trade{{4, a, "10"}},
+		},
+		{
+			name: "sell filled in several executions",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "10")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "3"), exec(at(4, 11, 1), "3"), exec(at(4, 11, 2), "4")),
+			},
+			want: []trade{{4, a, "10"}},
+		},
+		{
+			name: "buy filled in several executions",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "5"), exec(at(4, 10, 1), "5")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "10")),
+			},
+			want: []trade{{4, a, "10"}},
+		},
+		{
+			name: "two sell orders",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "10")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "4")),
+				order(a, robinhood.Side_Sell, exec(at(4, 12, 0), "6")),
+			},
+			want: []trade{{4, a, "4"}, {4, a, "6"}},
+		},
+		{
+			name: "held overnight",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "10")),
+				order(a, robinhood.Side_Sell, exec(at(5, 10, 0), "10")),
+			},
+		},
+		{
+			name: "sold before bought",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Sell, exec(at(4, 10, 0), "10")),
+				order(a, robinhood.Side_Buy, exec(at(4, 11, 0), "10")),
+			},
+		},
+		{
+			name: "sell larger than the day's buys",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "2")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "10")),
+			},
+			want: []trade{{4, a, "2"}},
+		},
+		{
+			name: "sell order over two days",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "5")),
+				order(a, robinhood.Side_Buy, exec(at(5, 10, 0), "5")),
+				order(a, robinhood.Side_Sell, exec(at(4, 15, 59), "5"), exec(at(5, 11, 0), "5")),
+			},
+			want: []trade{{4, a, "5"}, {5, a, "5"}},
+		},
+		{
+			name: "separate instruments",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(4, 10, 0), "1")),
+				order(b, robinhood.Side_Buy, exec(at(4, 10, 0), "1")),
+				order(b, robinhood.Side_Sell, exec(at(4, 11, 0), "1")),
+				order(a, robinhood.Side_Sell, exec(at(4, 11, 0), "1")),
+			},
+			want: []trade{{4, a, "1"}, {4, b, "1"}},
+		},
+		{
+			name: "before since",
+			orders: []robinhood.Order{
+				order(a, robinhood.Side_Buy, exec(at(1, 10, 0), "1")),
+				order(a, robinhood.Side_Sell, exec(at(1, 11, 0), "1")),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := robinhood.CountDayTrades(tt.orders, since)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d day trades %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if y, m, d := g.Date.Date(); y != 2024 || m != time.March || d != w.day || g.Instrument != w.instrument || g.Quantity.String() != w.quantity {
+					t.Errorf("day trade %d: got %v %s %s, want March %d %s %s", i, g.Date, g.Instrument, g.Quantity, w.day, w.instrument, w.quantity)
+				}
+			}
+		})
+	}
+}
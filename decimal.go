@@ -0,0 +1,271 @@
+package robinhood
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Decimal is an exact decimal number. The API sends prices, quantities and
+// amounts as decimal strings, which Decimal parses without the rounding
+// errors of float64. The zero value is 0.
+type Decimal struct {
+	// The value is unscaled / 10^scale. A nil unscaled is 0. The scale is
+	// never negative.
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns the Decimal unscaled * 10^-scale, e.g. NewDecimal(12345,
+// 2) is 123.45.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// DecimalFromInt returns the Decimal equal to i.
+func DecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// DecimalFromFloat returns the Decimal with the shortest decimal
+// representation that rounds to f. It panics if f is NaN or infinite.
+func DecimalFromFloat(f float64) Decimal {
+	return MustParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// maxDecimalExponent bounds the exponent ParseDecimal accepts, so that a
+// malformed number can't make it allocate a huge power of ten.
+const maxDecimalExponent = 1000
+
+// ParseDecimal parses a decimal number such as "-12.3400" or "1.5e-3". The
+// scale (digits after the point) of s is kept, so String returns s in
+// canonical form. Exponents beyond ±1000 are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	orig := s
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("robinhood: invalid decimal %q", orig)
+		}
+		if exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("robinhood: decimal %q out of range", orig)
+		}
+		s = s[:i]
+	}
+
+	digits := s
+	scale := int64(0)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = int64(len(s) - i - 1)
+	}
+	// Reject forms big.Int accepts but a decimal shouldn't have.
+	unsigned := strings.TrimLeft(digits, "+-")
+	if unsigned == "" || len(digits)-len(unsigned) > 1 || strings.ContainsAny(unsigned, "_+-") || strings.HasPrefix(unsigned, "0x") {
+		return Decimal{}, fmt.Errorf("robinhood: invalid decimal %q", orig)
+	}
+
+	u, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("robinhood: invalid decimal %q", orig)
+	}
+	scale -= exp
+	if scale < 0 {
+		u.Mul(u, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{unscaled: u, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s is invalid. It is
+// intended for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns d in decimal notation, with as many digits after the point
+// as its scale.
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero returns whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign returns -1, 0 or 1 as d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than e.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal returns whether d and e are numerically equal, regardless of scale.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{unscaled: a.Sub(a, b), scale: scale}
+}
+
+// Mul returns d * e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}
+}
+
+// Div returns d / e rounded half away from zero to places digits after the
+// point. A negative places rounds to a multiple of 10^-places. It panics if
+// e is 0.
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	// d/e = (a/10^sa) / (b/10^sb), so d/e * 10^places = a * 10^(places+sb-sa) / b.
+	a, b := new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	if shift := places + e.scale - d.scale; shift >= 0 {
+		a.Mul(a, pow10(shift))
+	} else {
+		b.Mul(b, pow10(-shift))
+	}
+	return scaled(quoRound(a, b), places)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Round returns d rounded half away from zero to places digits after the
+// point. A negative places rounds to a multiple of 10^-places. If d has no
+// more than places digits after the point, it is returned unchanged.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return d
+	}
+	return scaled(quoRound(d.int(), pow10(d.scale-places)), places)
+}
+
+// RoundToTick returns the multiple of tick nearest to d, rounding half away
+// from zero, e.g. to make a limit price acceptable for an Instrument's
+// MinTickSize. If tick is 0, d is returned unchanged.
+func (d Decimal) RoundToTick(tick Decimal) Decimal {
+	if tick.IsZero() {
+		return d
+	}
+	return d.Div(tick, 0).Mul(tick)
+}
+
+// MarshalJSON implements json.Marshaler. Decimals are encoded as strings, as
+// the API does.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a decimal string or
+// number; null and "" decode as 0.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*d = Decimal{}
+		return nil
+	}
+	parsed, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// scaled returns the Decimal u / 10^scale. A negative scale is folded into
+// the unscaled value.
+func scaled(u *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: u.Mul(u, pow10(-scale))}
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// int returns the unscaled value of d, which must not be modified.
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// align returns copies of the unscaled values of d and e at their common
+// scale.
+func align(d, e Decimal) (a, b *big.Int, scale int32) {
+	a, b = new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(e.scale-d.scale))
+		return a, b, e.scale
+	case e.scale < d.scale:
+		b.Mul(b, pow10(d.scale-e.scale))
+	}
+	return a, b, d.scale
+}
+
+// quoRound returns a / b rounded half away from zero.
+func quoRound(a, b *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(new(big.Int).Abs(b)) >= 0 {
+		if a.Sign() == b.Sign() {
+			q.Add(q, big.NewInt(1))
+		} else {
+			q.Sub(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// pow10 returns 10^n.
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
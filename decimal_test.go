@@ -0,0 +1,144 @@
+package robinhood_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"12", "12"},
+		{"+12", "12"},
+		{"-12.3400", "-12.3400"},
+		{"0.001", "0.001"},
+		{"-0.5", "-0.5"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"1.5e-3", "0.0015"},
+		{"1.5E3", "1500"},
+		{"1.25e1", "12.5"},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789"},
+		{"1e1000", "1" + strings.Repeat("0", 1000)},
+		{"1e-1000", "0." + strings.Repeat("0", 999) + "1"},
+	}
+	for _, tt := range tests {
+		d, err := robinhood.ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q): %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		// The canonical form round trips.
+		if d2, err := robinhood.ParseDecimal(tt.want); err != nil || d2.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %v, %v, want it unchanged", tt.want, d2, err)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "e3", "1e", "1.2.3", "--1", "+-1", "1-2", "0x10", "1_000", "abc", "1e2000000000", "1e1001", "1e-1001", "1e99999999999"} {
+		if d, err := robinhood.ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A, B, C, D robinhood.Decimal
+	}
+	if err := json.Unmarshal([]byte(`{"A":"1.50","B":2.25,"C":null,"D":""}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"A":"1.50","B":"2.25","C":"0","D":"0"}`; string(bs) != want {
+		t.Errorf("got %s, want %s", bs, want)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := robinhood.MustParseDecimal
+	tests := []struct {
+		name string
+		got  robinhood.Decimal
+		want string
+	}{
+		{"add", d("1.1").Add(d("2.25")), "3.35"},
+		{"add negative", d("1.1").Add(d("-2.25")), "-1.15"},
+		{"sub", d("0.3").Sub(d("0.1")), "0.2"},
+		{"mul", d("1.5").Mul(d("-0.25")), "-0.375"},
+		{"neg", d("1.5").Neg(), "-1.5"},
+		{"abs", d("-1.5").Abs(), "1.5"},
+		{"zero value", robinhood.Decimal{}.Add(d("1")), "1"},
+		{"new", robinhood.NewDecimal(12345, 2), "123.45"},
+		{"new negative scale", robinhood.NewDecimal(12, -2), "1200"},
+		{"from float", robinhood.DecimalFromFloat(0.1), "0.1"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if c := d("1.10").Cmp(d("1.1")); c != 0 || !d("1.10").Equal(d("1.1")) {
+		t.Errorf("1.10 and 1.1 compare %d, want equal", c)
+	}
+	if c := d("-2").Cmp(d("1.5")); c != -1 {
+		t.Errorf("-2 Cmp 1.5 = %d, want -1", c)
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	d := robinhood.MustParseDecimal
+	tests := []struct {
+		name string
+		got  robinhood.Decimal
+		want string
+	}{
+		{"div exact", d("10").Div(d("4"), 2), "2.50"},
+		{"div repeating", d("1").Div(d("3"), 4), "0.3333"},
+		{"div round up", d("2").Div(d("3"), 2), "0.67"},
+		{"div half up", d("1").Div(d("8"), 2), "0.13"},
+		{"div half negative", d("-1").Div(d("8"), 2), "-0.13"},
+		{"div half negative divisor", d("1").Div(d("-8"), 2), "-0.13"},
+		{"div both negative", d("-1").Div(d("-8"), 2), "0.13"},
+		{"div below half", d("1").Div(d("7"), 1), "0.1"},
+		{"div scaled", d("0.05").Div(d("0.2"), 3), "0.250"},
+		{"div to integer", d("7.5").Div(d("2.5"), 0), "3"},
+		{"round half", d("2.345").Round(2), "2.35"},
+		{"round half negative", d("-2.345").Round(2), "-2.35"},
+		{"round down", d("2.344").Round(2), "2.34"},
+		{"round to integer", d("-0.5").Round(0), "-1"},
+		{"round no-op", d("2.3").Round(2), "2.3"},
+		{"round to tens", d("1234").Round(-1), "1230"},
+		{"round to tens half", d("1235").Round(-1), "1240"},
+		{"round to hundreds", d("-1250.5").Round(-2), "-1300"},
+		{"round to thousands", d("499.99").Round(-3), "0"},
+		{"div to tens", d("1234").Div(robinhood.DecimalFromInt(1), -1), "1230"},
+		{"div to hundreds", d("1234").Div(robinhood.DecimalFromInt(1), -2), "1200"},
+		{"div to hundreds scaled", d("2500.5").Div(d("0.5"), -2), "5000"},
+		{"tick", d("10.03").RoundToTick(d("0.05")), "10.05"},
+		{"tick down", d("10.02").RoundToTick(d("0.05")), "10.00"},
+		{"tick half", d("10.025").RoundToTick(d("0.05")), "10.05"},
+		{"tick negative", d("-10.025").RoundToTick(d("0.05")), "-10.05"},
+		{"tick negative tick", d("10.03").RoundToTick(d("-0.05")), "10.05"},
+		{"tick penny", d("1.2345").RoundToTick(d("0.01")), "1.23"},
+		{"tick whole", d("17").RoundToTick(d("5")), "15"},
+		{"tick zero", d("1.2345").RoundToTick(robinhood.Decimal{}), "1.2345"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package robinhood
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewDeviceToken returns a random device token in the UUID form Robinhood
+// expects, e.g. "3fa85f64-5717-4562-b3fc-2c963f66afa6".
+//
+// Robinhood treats logins with a device token it has seen before as coming
+// from a known device, so a token should be generated once and reused; see
+// Creds.DeviceToken.
+func NewDeviceToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	// Version 4, variant 10xx, as for a random UUID.
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// ensureDeviceToken generates a DeviceToken for the Creds if they have none.
+func (c *Creds) ensureDeviceToken() error {
+	if c.DeviceToken != "" {
+		return nil
+	}
+	tkn, err := NewDeviceToken()
+	if err != nil {
+		return err
+	}
+	c.DeviceToken = tkn
+	return nil
+}
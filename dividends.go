@@ -0,0 +1,184 @@
+package robinhood
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const epDividends = "dividends/"
+
+type DividendState string
+
+const (
+	DividendState_Pending    DividendState = "pending"
+	DividendState_Paid       DividendState = "paid"
+	DividendState_Reinvested DividendState = "reinvested"
+	DividendState_Voided     DividendState = "voided"
+)
+
+// A Dividend is a dividend paid, or to be paid, on a position.
+type Dividend struct {
+	ID         string  `json:"id"`
+	Account    string  `json:"account"`
+	Instrument string  `json:"instrument"`
+	Position   Decimal `json:"position"`
+	// Dividend per share
+	Rate Decimal `json:"rate"`
+	// Total paid, before withholding
+	Amount         Decimal       `json:"amount"`
+	Withholding    Decimal       `json:"withholding"`
+	NRAWithholding Decimal       `json:"nra_withholding"`
+	State          DividendState `json:"state"`
+	DripEnabled    bool          `json:"drip_enabled"`
+	// Dates in YYYY-MM-DD form
+	RecordDate  string    `json:"record_date"`
+	PayableDate string    `json:"payable_date"`
+	PaidAt      time.Time `json:"paid_at"`
+	URL         string    `json:"url"`
+}
+
+// Payable returns the dividend's payable date, or the zero time if it has
+// none or it is malformed.
+func (d *Dividend) Payable() time.Time {
+	t, _ := time.Parse("2006-01-02", d.PayableDate)
+	return t
+}
+
+// A DividendFilter selects dividends by their fields. Its zero value selects
+// every dividend.
+type DividendFilter struct {
+	// State, if set, selects dividends in that state.
+	State DividendState
+	// Instrument, if set, selects dividends on the instrument with that URL.
+	Instrument string
+	// Since and Until, if set, select dividends payable on or after Since
+	// and before Until.
+	Since, Until time.Time
+}
+
+// match returns whether d is selected by the filter.
+func (f *DividendFilter) match(d *Dividend) bool {
+	if f == nil {
+		return true
+	}
+	if f.State != "" && d.State != f.State {
+		return false
+	}
+	if f.Instrument != "" && d.Instrument != f.Instrument {
+		return false
+	}
+	payable := d.Payable()
+	if !f.Since.IsZero() && payable.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !payable.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// GetDividends returns the user's dividends selected by f, which may be nil
+// to return them all.
+func (c *Client) GetDividends(f *DividendFilter) ([]Dividend, error) {
+	return c.GetDividendsContext(context.Background(), f)
+}
+
+// GetDividendsContext is like GetDividends but every page request is bound to
+// ctx.
+func (c *Client) GetDividendsContext(ctx context.Context, f *DividendFilter) ([]Dividend, error) {
+	var divs []Dividend
+	p := NewPager[Dividend](c, c.url(epDividends))
+	for p.HasNext() {
+		results, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range results {
+			if f.match(&d) {
+				divs = append(divs, d)
+			}
+		}
+	}
+	return divs, nil
+}
+
+// A DividendTotal is the sum of a group of dividends. Paid includes
+// reinvested dividends; voided dividends are not counted.
+type DividendTotal struct {
+	// Instrument is set for totals by instrument.
+	Instrument *Instrument
+	// Year is set for totals by year.
+	Year    int
+	Paid    Decimal
+	Pending Decimal
+}
+
+// add counts d in the total.
+func (t *DividendTotal) add(d *Dividend) {
+	switch d.State {
+	case DividendState_Paid, DividendState_Reinvested:
+		t.Paid = t.Paid.Add(d.Amount)
+	case DividendState_Pending:
+		t.Pending = t.Pending.Add(d.Amount)
+	}
+}
+
+// DividendsByYear totals divs by the year they are payable in, in ascending
+// order of year.
+func DividendsByYear(divs []Dividend) []DividendTotal {
+	byYear := map[int]*DividendTotal{}
+	for i := range divs {
+		year := divs[i].Payable().Year()
+		t, ok := byYear[year]
+		if !ok {
+			t = &DividendTotal{Year: year}
+			byYear[year] = t
+		}
+		t.add(&divs[i])
+	}
+
+	totals := make([]DividendTotal, 0, len(byYear))
+	for _, t := range byYear {
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Year < totals[j].Year })
+	return totals
+}
+
+// DividendsByInstrument totals divs by instrument, in order of symbol,
+// looking each instrument up with GetInstrument.
+func (c *Client) DividendsByInstrument(divs []Dividend) ([]DividendTotal, error) {
+	return c.DividendsByInstrumentContext(context.Background(), divs)
+}
+
+// DividendsByInstrumentContext is like DividendsByInstrument but the
+// instrument lookups are bound to ctx.
+func (c *Client) DividendsByInstrumentContext(ctx context.Context, divs []Dividend) ([]DividendTotal, error) {
+	byURL := map[string]*DividendTotal{}
+	var urls []string
+	for i := range divs {
+		t, ok := byURL[divs[i].Instrument]
+		if !ok {
+			t = &DividendTotal{}
+			byURL[divs[i].Instrument] = t
+			urls = append(urls, divs[i].Instrument)
+		}
+		t.add(&divs[i])
+	}
+
+	insts, errs := c.getInstruments(ctx, urls)
+	totals := make([]DividendTotal, len(urls))
+	for i, url := range urls {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if insts[i] == nil {
+			return nil, ctx.Err()
+		}
+		byURL[url].Instrument = insts[i]
+		totals[i] = *byURL[url]
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Instrument.Symbol < totals[j].Instrument.Symbol })
+	return totals, nil
+}
@@ -0,0 +1,45 @@
+package robinhood_test
+
+import (
+	"testing"
+
+	"astuart.co/go-robinhood"
+)
+
+func TestDividendsByYear(t *testing.T) {
+	d := robinhood.MustParseDecimal
+	div := func(payable string, state robinhood.DividendState, amount string) robinhood.Dividend {
+		return robinhood.Dividend{PayableDate: payable, State: state, Amount: d(amount)}
+	}
+	divs := []robinhood.Dividend{
+		div("2025-03-13", robinhood.DividendState_Pending, "4.00"),
+		div("2023-12-31", robinhood.DividendState_Paid, "1.00"),
+		div("2024-01-01", robinhood.DividendState_Reinvested, "2.00"),
+		div("2024-06-30", robinhood.DividendState_Paid, "0.50"),
+		div("2024-07-01", robinhood.DividendState_Voided, "9.99"),
+		div("2025-01-02", robinhood.DividendState_Paid, "1.25"),
+	}
+	want := []struct {
+		year          int
+		paid, pending string
+	}{
+		{2023, "1.00", "0"},
+		{2024, "2.50", "0"},
+		{2025, "1.25", "4.00"},
+	}
+
+	got := robinhood.DividendsByYear(divs)
+	if len(got) != len(want) {
+		t.Fatalf("got %d years, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Year != w.year || !g.Paid.Equal(d(w.paid)) || !g.Pending.Equal(d(w.pending)) || g.Instrument != nil {
+			t.Errorf("year %d: got %d paid %s pending %s, want %d paid %s pending %s", i, g.Year, g.Paid, g.Pending, w.year, w.paid, w.pending)
+		}
+	}
+
+	if got := robinhood.DividendsByYear(nil); len(got) != 0 {
+		t.Errorf("DividendsByYear(nil) = %v, want none", got)
+	}
+}
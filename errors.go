@@ -0,0 +1,128 @@
+package robinhood
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// An APIError is returned when the Robinhood API responds with an error status
+// or an error detail.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method and Endpoint identify the request that failed.
+	Method   string
+	Endpoint string
+	// Header and Body are the raw response headers and body.
+	Header http.Header
+	Body   []byte
+
+	// Detail is the "detail" message of the response, if any.
+	Detail string
+	// NonFieldErrors are validation errors that don't apply to a single
+	// request field.
+	NonFieldErrors []string
+	// FieldErrors maps request field names to their validation errors.
+	FieldErrors map[string][]string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	var msgs []string
+	if e.Detail != "" {
+		msgs = append(msgs, e.Detail)
+	}
+	msgs = append(msgs, e.NonFieldErrors...)
+
+	fields := make([]string, 0, len(e.FieldErrors))
+	for f := range e.FieldErrors {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		msgs = append(msgs, f+": "+strings.Join(e.FieldErrors[f], " "))
+	}
+
+	if len(msgs) == 0 {
+		return fmt.Sprintf("robinhood: %s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("robinhood: %s %s: %d: %s", e.Method, e.Endpoint, e.StatusCode, strings.Join(msgs, "; "))
+}
+
+// newAPIError builds an APIError from a response and its body, filling in the
+// error messages from the body if it is a JSON object.
+func newAPIError(res *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.Endpoint = res.Request.URL.String()
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return e
+	}
+
+	for k, v := range fields {
+		switch k {
+		case "detail":
+			json.Unmarshal(v, &e.Detail)
+		case "non_field_errors":
+			e.NonFieldErrors = errorStrings(v)
+		default:
+			if msgs := errorStrings(v); msgs != nil {
+				if e.FieldErrors == nil {
+					e.FieldErrors = map[string][]string{}
+				}
+				e.FieldErrors[k] = msgs
+			}
+		}
+	}
+	return e
+}
+
+// errorStrings decodes an error value that may be a single string or a list
+// of strings. It returns nil for any other JSON value.
+func errorStrings(v json.RawMessage) []string {
+	var list []string
+	if json.Unmarshal(v, &list) == nil {
+		return list
+	}
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return []string{s}
+	}
+	return nil
+}
+
+// hasStatus reports whether err is an *APIError with the given status code.
+func hasStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
+
+// IsUnauthorized returns whether err is an APIError caused by a missing,
+// invalid or expired token.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsRateLimited returns whether err is an APIError caused by Robinhood
+// throttling requests.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsNotFound returns whether err is an APIError for a resource that does not
+// exist.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
@@ -1,5 +1,16 @@
 package robinhood
 
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// maxInstrumentLookups is the most instruments looked up at once by
+// getInstruments.
+const maxInstrumentLookups = 4
+
 type Instrument struct {
 	BloombergUnique    string      `json:"bloomberg_unique"`
 	Country            string      `json:"country"`
@@ -10,7 +21,7 @@ type Instrument struct {
 	MaintenanceRatio   string      `json:"maintenance_ratio"`
 	MarginInitialRatio string      `json:"margin_initial_ratio"`
 	Market             string      `json:"market"`
-	MinTickSize        interface{} `json:"min_tick_size"`
+	MinTickSize        Decimal     `json:"min_tick_size"`
 	Name               string      `json:"name"`
 	Quote              string      `json:"quote"`
 	SimpleName         interface{} `json:"simple_name"`
@@ -26,9 +37,15 @@ func (resp *Instrument) Details() string {
 	return resp.Detail
 }
 
+// GetInstrument returns the Instrument found at instURL.
 func (c Client) GetInstrument(instURL string) (*Instrument, error) {
+	return c.GetInstrumentContext(context.Background(), instURL)
+}
+
+// GetInstrumentContext is like GetInstrument but the request is bound to ctx.
+func (c Client) GetInstrumentContext(ctx context.Context, instURL string) (*Instrument, error) {
 	var i Instrument
-	err := c.GetAndDecode(instURL, &i)
+	err := c.GetAndDecodeContext(ctx, instURL, &i)
 	return &i, err
 }
 
@@ -41,8 +58,54 @@ func (resp *GetInstrumentsResponse) Details() string {
 	return resp.Detail
 }
 
+// GetInstrumentForSymbol returns the Instrument for the given ticker symbol.
 func (c Client) GetInstrumentForSymbol(sym string) (*Instrument, error) {
+	return c.GetInstrumentForSymbolContext(context.Background(), sym)
+}
+
+// GetInstrumentForSymbolContext is like GetInstrumentForSymbol but the request
+// is bound to ctx.
+func (c Client) GetInstrumentForSymbolContext(ctx context.Context, sym string) (*Instrument, error) {
 	var i GetInstrumentsResponse
-	err := c.GetAndDecode(epInstruments+"?symbol="+sym, &i)
-	return &i.Results[0], err
+	err := c.GetAndDecodeContext(ctx, c.url(epInstruments)+"?symbol="+url.QueryEscape(sym), &i)
+	if err != nil {
+		return nil, err
+	}
+	if len(i.Results) == 0 {
+		return nil, fmt.Errorf("robinhood: no instrument for symbol %q", sym)
+	}
+	return &i.Results[0], nil
+}
+
+// getInstruments looks up the instruments at urls with a fixed pool of
+// maxInstrumentLookups workers; the Client's rate limiter paces them
+// further. Once ctx is done no more lookups are started, and the instruments
+// not looked up are left nil with a nil error.
+func (c *Client) getInstruments(ctx context.Context, urls []string) ([]*Instrument, []error) {
+	insts := make([]*Instrument, len(urls))
+	errs := make([]error, len(urls))
+
+	jobs := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < min(maxInstrumentLookups, len(urls)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				insts[i], errs[i] = c.GetInstrumentContext(ctx, urls[i])
+			}
+		}()
+	}
+
+feed:
+	for i := range urls {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return insts, errs
 }
@@ -0,0 +1,28 @@
+package robinhood_test
+
+import (
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+func TestGetInstrumentForSymbol(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	want := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst, err := c.GetInstrumentForSymbol("AAPL")
+	if err != nil || inst.URL != want.URL {
+		t.Errorf("GetInstrumentForSymbol(AAPL) = %+v, %v", inst, err)
+	}
+
+	inst, err = c.GetInstrumentForSymbol("NOPE")
+	if err == nil || inst != nil {
+		t.Errorf("GetInstrumentForSymbol(NOPE) = %+v, %v; want an error", inst, err)
+	}
+}
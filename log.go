@@ -0,0 +1,201 @@
+package robinhood
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// A RequestLog describes a single HTTP exchange with the Robinhood API.
+// Passwords, MFA codes, tokens and the Authorization header are redacted
+// before it is handed to a Logger.
+type RequestLog struct {
+	Method       string
+	URL          string
+	Header       http.Header
+	RequestBody  []byte
+	StatusCode   int
+	ResponseBody []byte
+	Latency      time.Duration
+	// Err is set if no response was received.
+	Err error
+}
+
+// A Logger receives a RequestLog for every request made by a Client or by
+// Creds when logging in.
+type Logger interface {
+	LogRequest(RequestLog)
+}
+
+// LoggerFunc adapts a function to the Logger interface.
+type LoggerFunc func(RequestLog)
+
+// LogRequest implements Logger.
+func (f LoggerFunc) LogRequest(l RequestLog) {
+	f(l)
+}
+
+// WithLogger makes the Client report each request it makes to l.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// NewTextLogger returns a Logger that writes a human readable description of
+// each request to w.
+func NewTextLogger(w io.Writer) Logger {
+	return LoggerFunc(func(l RequestLog) {
+		if l.Err != nil {
+			fmt.Fprintf(w, "%s %s: %v (%v)\n", l.Method, l.URL, l.Err, l.Latency)
+		} else {
+			fmt.Fprintf(w, "%s %s: %d (%v)\n", l.Method, l.URL, l.StatusCode, l.Latency)
+		}
+		if len(l.RequestBody) > 0 {
+			fmt.Fprintf(w, "> %s\n", l.RequestBody)
+		}
+		if len(l.ResponseBody) > 0 {
+			fmt.Fprintf(w, "< %s\n", l.ResponseBody)
+		}
+	})
+}
+
+// redactedFields are the body fields whose values are never logged. Keep it
+// in sync with scrubbedFields in robinhoodtest/cassette.go.
+var redactedFields = map[string]bool{
+	"password":      true,
+	"mfa_code":      true,
+	"access_token":  true,
+	"refresh_token": true,
+	"token":         true,
+	"device_token":  true,
+	"response":      true,
+}
+
+// redactedHeaders are the request headers whose values are never logged.
+var redactedHeaders = []string{"Authorization"}
+
+const redacted = "REDACTED"
+
+// loggingTransport reports each request it passes to next to a Logger.
+type loggingTransport struct {
+	logger Logger
+	next   http.RoundTripper
+}
+
+// newLoggingTransport wraps next so that requests are reported to l. If l is
+// nil, next is returned unchanged, unless the deprecated DebugMode is set.
+func newLoggingTransport(l Logger, next http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		if !DebugMode {
+			return next
+		}
+		l = NewTextLogger(os.Stdout)
+	}
+	return &loggingTransport{logger: l, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := RequestLog{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: redactHeader(req.Header),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			bs, _ := ioutil.ReadAll(body)
+			l.RequestBody = redactBody(bs)
+		}
+	}
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		l.Latency = time.Since(start)
+		l.Err = err
+		t.logger.LogRequest(l)
+		return nil, err
+	}
+
+	// Buffer the body so it can be both logged and decoded.
+	bs, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	l.Latency = time.Since(start)
+	if err != nil {
+		l.Err = err
+		t.logger.LogRequest(l)
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(bs))
+
+	l.StatusCode = res.StatusCode
+	l.ResponseBody = redactBody(bs)
+	t.logger.LogRequest(l)
+	return res, nil
+}
+
+// redactHeader returns a copy of h with sensitive values replaced.
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redactedHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+// redactBody returns a copy of a JSON or form encoded body with the values of
+// redactedFields replaced. Other bodies are returned as is.
+func redactBody(body []byte) []byte {
+	var v interface{}
+	if json.Unmarshal(body, &v) == nil {
+		bs, err := json.Marshal(redactJSON(v))
+		if err != nil {
+			return body
+		}
+		return bs
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+	changed := false
+	for k := range form {
+		if redactedFields[k] {
+			form.Set(k, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	return []byte(form.Encode())
+}
+
+// redactJSON replaces the values of redactedFields anywhere within v.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if redactedFields[k] {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(val)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
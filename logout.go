@@ -0,0 +1,103 @@
+package robinhood
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// ErrLoggedOut is returned by requests made with a Client after Logout.
+var ErrLoggedOut = errors.New("robinhood: client has logged out")
+
+// A TokenClearer is a TokenGetter that keeps tokens, e.g. in a cache, and can
+// forget them so they are not used again.
+type TokenClearer interface {
+	ClearToken() error
+}
+
+// revokeRequest is the body of a token revocation.
+type revokeRequest struct {
+	ClientId string `json:"client_id"`
+	Token    string `json:"token"`
+}
+
+type revokeResponse struct {
+	Detail string `json:"detail"`
+}
+
+func (resp *revokeResponse) Details() string {
+	return resp.Detail
+}
+
+// Logout revokes the Client's access and refresh tokens and clears them from
+// the Client's TokenGetter if it is a TokenClearer. Afterwards every request
+// made with the Client fails with ErrLoggedOut.
+func (c *Client) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout but the revocation requests are bound to ctx.
+// The Client is logged out even if they fail.
+func (c *Client) LogoutContext(ctx context.Context) error {
+	tok := c.auth.current()
+	if tok == nil {
+		return ErrLoggedOut
+	}
+	// Log out before revoking, so that a revocation rejected as unauthorized
+	// can't make the Client log in again.
+	c.auth.logout()
+
+	clientID := defaultClientID
+	if creds, ok := c.tokens.(*Creds); ok && creds.ClientId != "" {
+		clientID = creds.ClientId
+	}
+
+	// The revocations are authorized with the access token, but sent outside
+	// the Client's authTransport, which would renew a rejected token.
+	header := http.Header{"Authorization": {"Bearer " + tok.AccessToken}}
+	client := c.loginClient(nil)
+
+	var errs []error
+	for _, t := range []string{tok.RefreshToken, tok.AccessToken} {
+		if t == "" {
+			continue
+		}
+		var r revokeResponse
+		err := unauthenticatedPostAndDecode(ctx, client, c.url(epRevoke), revokeRequest{ClientId: clientID, Token: t}, &r, header)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if tc, ok := c.tokens.(TokenClearer); ok {
+		if err := tc.ClearToken(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ClearToken implements TokenClearer by removing the cache file. A cached
+// device token is kept.
+func (c *CredsCacher) ClearToken() error {
+	unlock, err := c.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cached, err := c.read()
+	if err != nil {
+		return err
+	}
+	if cached != nil && cached.DeviceToken != "" {
+		return c.write(&cachedToken{DeviceToken: cached.DeviceToken})
+	}
+
+	err = os.Remove(c.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
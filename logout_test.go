@@ -0,0 +1,107 @@
+package robinhood_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+func TestLogout(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	cacher := &robinhood.CredsCacher{Creds: robinhood.NewCreds("bob", "hunter2"), Path: t.TempDir() + "/token"}
+	c, err := robinhood.Dial(cacher, robinhood.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	tkn := robinhood.Token(c.AccessToken())
+
+	if err := c.Logout(); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := c.GetAccounts(); !errors.Is(err, robinhood.ErrLoggedOut) {
+		t.Errorf("GetAccounts after Logout: got %v, want ErrLoggedOut", err)
+	}
+	if err := c.Logout(); !errors.Is(err, robinhood.ErrLoggedOut) {
+		t.Errorf("second Logout: got %v, want ErrLoggedOut", err)
+	}
+
+	// The token was revoked on the server too.
+	c, err = robinhood.Dial(&tkn, robinhood.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAccounts(); !robinhood.IsUnauthorized(err) {
+		t.Errorf("revoked token: got %v, want unauthorized", err)
+	}
+}
+
+// rejectingTransport rejects revocations of any token but the first as
+// unauthorized, as servers that revoke an access token along with its
+// refresh token do.
+type rejectingTransport struct {
+	recordingTransport
+	revoked int
+}
+
+func (t *rejectingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/oauth2/revoke_token/" {
+		t.revoked++
+		if t.revoked > 1 {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"detail":"Invalid token."}`)),
+				Request:    req,
+			}, nil
+		}
+	}
+	return t.recordingTransport.RoundTrip(req)
+}
+
+func TestLogoutDoesNotLogInAgain(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+
+	rt := &rejectingTransport{}
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"), robinhood.WithBaseURL(s.URL), robinhood.WithTransport(rt))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if err := c.Logout(); !robinhood.IsUnauthorized(err) {
+		t.Errorf("Logout: got %v, want the unauthorized revocation", err)
+	}
+	if n := rt.count("/oauth2/token/"); n != 1 {
+		t.Errorf("%d logins, want only the first", n)
+	}
+}
+
+func TestLogoutRedactsTokens(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+
+	var log bytes.Buffer
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"), robinhood.WithBaseURL(s.URL), robinhood.WithLogger(robinhood.NewTextLogger(&log)))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if err := c.Logout(); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	for _, secret := range []string{"hunter2", "test-token-", "test-refresh-"} {
+		if strings.Contains(log.String(), secret) {
+			t.Errorf("log contains %q:\n%s", secret, log.String())
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package robinhood
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMFAAttempts is how many codes are requested from an MFAProvider for an
+// authenticator app login before giving up.
+const maxMFAAttempts = 3
+
+// A ChallengeType is a way in which Robinhood sends or expects a verification
+// code.
+type ChallengeType string
+
+const (
+	ChallengeType_SMS   ChallengeType = "sms"
+	ChallengeType_Email ChallengeType = "email"
+	// ChallengeType_App is a code from an authenticator app.
+	ChallengeType_App ChallengeType = "app"
+)
+
+// An MFAChallenge is a request for a verification code made during login.
+type MFAChallenge struct {
+	// ID identifies an SMS or email challenge. It is empty for
+	// authenticator app codes.
+	ID                string        `json:"id"`
+	Type              ChallengeType `json:"type"`
+	Status            string        `json:"status"`
+	RemainingAttempts int           `json:"remaining_attempts"`
+	ExpiresAt         time.Time     `json:"expires_at"`
+
+	// Attempt counts the codes requested for this challenge, starting at
+	// 1. It is incremented each time a code is rejected.
+	Attempt int `json:"-"`
+}
+
+// An MFAProvider supplies verification codes when logging in requires them.
+type MFAProvider interface {
+	MFACode(ctx context.Context, ch *MFAChallenge) (string, error)
+}
+
+// MFAProviderFunc adapts a function to the MFAProvider interface.
+type MFAProviderFunc func(ctx context.Context, ch *MFAChallenge) (string, error)
+
+// MFACode implements MFAProvider.
+func (f MFAProviderFunc) MFACode(ctx context.Context, ch *MFAChallenge) (string, error) {
+	return f(ctx, ch)
+}
+
+// A PromptMFA is an MFAProvider that asks for codes interactively, e.g. from
+// a CLI.
+//
+// Lines are read from In by a single goroutine that runs until In returns an
+// error, so a prompt abandoned when its context is done leaves its line, once
+// entered, to the next prompt.
+type PromptMFA struct {
+	In  io.Reader
+	Out io.Writer
+
+	once  sync.Once
+	lines chan string
+	err   error
+}
+
+// read sends the lines read from p.In to p.lines until an error, which it
+// records in p.err before closing p.lines.
+func (p *PromptMFA) read() {
+	r := bufio.NewReader(p.In)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			p.lines <- line
+		}
+		if err != nil {
+			p.err = err
+			close(p.lines)
+			return
+		}
+	}
+}
+
+// NewStdinMFA returns a PromptMFA that prompts on stderr and reads codes from
+// stdin.
+func NewStdinMFA() *PromptMFA {
+	return &PromptMFA{In: os.Stdin, Out: os.Stderr}
+}
+
+// MFACode implements MFAProvider.
+func (p *PromptMFA) MFACode(ctx context.Context, ch *MFAChallenge) (string, error) {
+	p.once.Do(func() {
+		p.lines = make(chan string)
+		go p.read()
+	})
+
+	if ch.Attempt > 1 {
+		fmt.Fprint(p.Out, "That code was not accepted. ")
+	}
+	switch ch.Type {
+	case ChallengeType_SMS:
+		fmt.Fprint(p.Out, "Enter the code sent to you by text message: ")
+	case ChallengeType_Email:
+		fmt.Fprint(p.Out, "Enter the code sent to you by email: ")
+	default:
+		fmt.Fprint(p.Out, "Enter the code from your authenticator app: ")
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case line, ok := <-p.lines:
+		if !ok {
+			return "", p.err
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
+// challengeResponse is the API's reply to a challenge response: the updated
+// challenge on success, or an error detail with the challenge on failure.
+type challengeResponse struct {
+	MFAChallenge
+	Challenge *MFAChallenge `json:"challenge"`
+	Detail    string        `json:"detail"`
+}
+
+func (resp *challengeResponse) Details() string {
+	return resp.Detail
+}
+
+// loginWithMFA completes a login that requires an authenticator app code by
+// asking c.MFAProvider for one, retrying with a fresh code if it is
+// rejected.
+func (c *Creds) loginWithMFA(ctx context.Context, mfaType string) (*OAuthToken, error) {
+	if c.MFAProvider == nil {
+		return nil, fmt.Errorf("this account requires two factor. Two factor type: %v", mfaType)
+	}
+
+	ch := &MFAChallenge{Type: ChallengeType(mfaType)}
+	if ch.Type == "" {
+		ch.Type = ChallengeType_App
+	}
+
+	var err error
+	for ch.Attempt = 1; ch.Attempt <= maxMFAAttempts; ch.Attempt++ {
+		var code string
+		code, err = c.MFAProvider.MFACode(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+
+		body := *c
+		body.MFA = code
+		var resp LoginResponse
+		now := time.Now()
+		err = unauthenticatedPostAndDecode(ctx, c.httpClient(ctx), c.url(ctx, epLogin), &body, &resp, nil)
+		if err == nil && !resp.MFARequired {
+			return resp.token(now), nil
+		}
+
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("robinhood: two factor code rejected %d times: %v", maxMFAAttempts, err)
+}
+
+// loginWithChallenge completes a login that was blocked by an SMS or email
+// challenge by asking c.MFAProvider for the code that was sent, and then
+// logging in again referencing the validated challenge.
+func (c *Creds) loginWithChallenge(ctx context.Context, ch *MFAChallenge) (*OAuthToken, error) {
+	if c.MFAProvider == nil {
+		return nil, fmt.Errorf("this account requires verification. Challenge type: %v", ch.Type)
+	}
+
+	for ch.Attempt = 1; ch.Status != "validated"; ch.Attempt++ {
+		code, err := c.MFAProvider.MFACode(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp challengeResponse
+		err = unauthenticatedPostAndDecode(ctx, c.httpClient(ctx), c.url(ctx, epChallenge+ch.ID+"/respond/"), map[string]string{"response": code}, &resp, nil)
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest) {
+			return nil, err
+		}
+		if resp.Challenge != nil {
+			resp.MFAChallenge = *resp.Challenge
+		}
+		if resp.ID != "" {
+			attempt := ch.Attempt
+			*ch = resp.MFAChallenge
+			ch.Attempt = attempt
+		} else if err != nil {
+			ch.RemainingAttempts--
+		}
+		if ch.Status != "validated" {
+			if err == nil {
+				return nil, fmt.Errorf("robinhood: verification failed: challenge %s", ch.Status)
+			}
+			if ch.RemainingAttempts <= 0 {
+				return nil, fmt.Errorf("robinhood: verification failed: %w", err)
+			}
+		}
+	}
+
+	var resp LoginResponse
+	now := time.Now()
+	header := http.Header{"X-Robinhood-Challenge-Response-Id": {ch.ID}}
+	err := unauthenticatedPostAndDecode(ctx, c.httpClient(ctx), c.url(ctx, epLogin), c, &resp, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.MFARequired {
+		return c.loginWithMFA(ctx, resp.MFAType)
+	}
+	return resp.token(now), nil
+}
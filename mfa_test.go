@@ -0,0 +1,210 @@
+package robinhood_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+// codes returns an MFAProvider that supplies each of cs in turn, and records
+// the challenges it is asked about.
+func codes(chs *[]robinhood.MFAChallenge, cs ...string) robinhood.MFAProvider {
+	return robinhood.MFAProviderFunc(func(ctx context.Context, ch *robinhood.MFAChallenge) (string, error) {
+		*chs = append(*chs, *ch)
+		if len(cs) == 0 {
+			return "", errors.New("out of codes")
+		}
+		c := cs[0]
+		cs = cs[1:]
+		return c, nil
+	})
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "123456")
+
+	var chs []robinhood.MFAChallenge
+	creds := robinhood.NewCreds("bob", "hunter2")
+	creds.MFAProvider = codes(&chs, "000000", "123456")
+	if _, err := robinhood.Dial(creds, robinhood.WithBaseURL(s.URL)); err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if len(chs) != 2 {
+		t.Fatalf("%d codes requested, want 2", len(chs))
+	}
+	for i, ch := range chs {
+		if ch.Type != robinhood.ChallengeType_App || ch.Attempt != i+1 {
+			t.Errorf("request %d: got %s attempt %d, want app attempt %d", i, ch.Type, ch.Attempt, i+1)
+		}
+	}
+
+	chs = nil
+	creds = robinhood.NewCreds("bob", "hunter2")
+	creds.MFAProvider = codes(&chs, "1", "2", "3", "4")
+	_, err := robinhood.Dial(creds, robinhood.WithBaseURL(s.URL))
+	if err == nil || !strings.Contains(err.Error(), "rejected 3 times") {
+		t.Errorf("Dial with wrong codes: got %v, want rejected 3 times", err)
+	}
+	if len(chs) != 3 {
+		t.Errorf("%d codes requested, want 3", len(chs))
+	}
+}
+
+func TestLoginWithChallenge(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+	s.RequireChallenge("bob", robinhood.ChallengeType_SMS, "424242")
+
+	var log bytes.Buffer
+	var chs []robinhood.MFAChallenge
+	creds := robinhood.NewCreds("bob", "hunter2")
+	creds.MFAProvider = codes(&chs, "000000", "424242")
+	c, err := robinhood.Dial(creds, robinhood.WithBaseURL(s.URL), robinhood.WithLogger(robinhood.NewTextLogger(&log)))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if len(chs) != 2 {
+		t.Fatalf("%d codes requested, want 2", len(chs))
+	}
+	if ch := chs[1]; ch.Type != robinhood.ChallengeType_SMS || ch.Attempt != 2 || ch.RemainingAttempts != 2 {
+		t.Errorf("second request: got %s attempt %d with %d remaining, want sms attempt 2 with 2 remaining", ch.Type, ch.Attempt, ch.RemainingAttempts)
+	}
+	if strings.Contains(log.String(), "424242") {
+		t.Errorf("log contains the challenge code:\n%s", log.String())
+	}
+
+	chs = nil
+	creds = robinhood.NewCreds("bob", "hunter2")
+	creds.MFAProvider = codes(&chs, "1", "2", "3", "4")
+	_, err = robinhood.Dial(creds, robinhood.WithBaseURL(s.URL))
+	var apiErr *robinhood.APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "verification failed") {
+		t.Errorf("Dial with wrong codes: got %v, want verification failed APIError", err)
+	}
+	if len(chs) != 3 {
+		t.Errorf("%d codes requested, want 3", len(chs))
+	}
+}
+
+func TestCredsCacherReusesDeviceToken(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+	s.RequireChallenge("bob", robinhood.ChallengeType_SMS, "424242")
+	path := filepath.Join(t.TempDir(), "token.json")
+
+	dial := func(path string, cs ...string) (*robinhood.Creds, []robinhood.MFAChallenge, error) {
+		t.Helper()
+		var chs []robinhood.MFAChallenge
+		creds := robinhood.NewCreds("bob", "hunter2")
+		creds.MFAProvider = codes(&chs, cs...)
+		c, err := robinhood.Dial(&robinhood.CredsCacher{Creds: creds, Path: path}, robinhood.WithBaseURL(s.URL))
+		if err != nil {
+			return creds, chs, err
+		}
+		if _, err := c.GetAccounts(); err != nil {
+			t.Fatalf("GetAccounts: %v", err)
+		}
+		// Logging out clears the cached token, so the next Dial logs in.
+		if err := c.Logout(); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		return creds, chs, nil
+	}
+
+	first, chs, err := dial(path, "424242")
+	if err != nil || len(chs) != 1 {
+		t.Fatalf("first login: got %v after %d codes, want success after 1", err, len(chs))
+	}
+	if first.DeviceToken == "" {
+		t.Fatal("first login sent no device token")
+	}
+
+	second, chs, err := dial(path)
+	if err != nil || len(chs) != 0 {
+		t.Fatalf("login with the cached device token: got %v after %d codes, want success without a challenge", err, len(chs))
+	}
+	if second.DeviceToken != first.DeviceToken {
+		t.Errorf("second login sent device token %q, want the cached %q", second.DeviceToken, first.DeviceToken)
+	}
+
+	// A cache elsewhere holds no device token, so the login is challenged.
+	other, chs, err := dial(filepath.Join(t.TempDir(), "token.json"))
+	if err == nil || len(chs) != 1 {
+		t.Errorf("login with a new cache: got %v after %d codes, want a challenge", err, len(chs))
+	}
+	if other.DeviceToken == first.DeviceToken {
+		t.Errorf("login with a new cache reused device token %q", first.DeviceToken)
+	}
+}
+
+func TestLoginWithChallengeNotValidated(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth2/token/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"detail":    "Request blocked, challenge issued.",
+			"challenge": robinhood.MFAChallenge{ID: "ch1", Type: robinhood.ChallengeType_Email, Status: "issued", RemainingAttempts: 3},
+		})
+	})
+	mux.HandleFunc("POST /challenge/ch1/respond/", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(robinhood.MFAChallenge{ID: "ch1", Type: robinhood.ChallengeType_Email, Status: "expired"})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	var chs []robinhood.MFAChallenge
+	creds := robinhood.NewCreds("bob", "hunter2")
+	creds.MFAProvider = codes(&chs, "424242")
+	_, err := robinhood.Dial(creds, robinhood.WithBaseURL(s.URL+"/"))
+	if err == nil || !strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "<nil>") {
+		t.Errorf("Dial: got %v, want the challenge's status", err)
+	}
+}
+
+func TestPromptMFA(t *testing.T) {
+	r, w := io.Pipe()
+	p := &robinhood.PromptMFA{In: r, Out: io.Discard}
+	ch := &robinhood.MFAChallenge{Type: robinhood.ChallengeType_App, Attempt: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.MFACode(ctx, ch); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled MFACode: got %v, want context.Canceled", err)
+	}
+
+	// The line entered after the canceled prompt answers the next one.
+	go func() {
+		io.WriteString(w, " 123456 \n654321\n")
+		w.Close()
+	}()
+	for _, want := range []string{"123456", "654321"} {
+		code, err := p.MFACode(context.Background(), ch)
+		if err != nil || code != want {
+			t.Errorf("MFACode: got %q, %v, want %q", code, err, want)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := p.MFACode(context.Background(), ch); err != io.EOF {
+			t.Errorf("MFACode at EOF: got %v, want io.EOF", err)
+		}
+	}
+}
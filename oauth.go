@@ -0,0 +1,236 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshLeeway is how long before its expiry a Client renews a token.
+const tokenRefreshLeeway = time.Minute
+
+// An OAuthToken is an access token along with the refresh token and expiry
+// the API issued it with.
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Expired returns whether the token expires within d from now. A token with
+// no Expiry never expires.
+func (t *OAuthToken) Expired(d time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(d).After(t.Expiry)
+}
+
+// A TokenRefresher is a TokenGetter that can also report when its tokens
+// expire and renew them. A Client dialed with a TokenRefresher renews its
+// token shortly before it expires, and once if a request is rejected as
+// unauthorized, without the caller having to Dial again.
+type TokenRefresher interface {
+	TokenGetter
+	// Login obtains a new token.
+	Login(ctx context.Context) (*OAuthToken, error)
+	// Refresh exchanges tok for a new token, typically using its
+	// RefreshToken.
+	Refresh(ctx context.Context, tok *OAuthToken) (*OAuthToken, error)
+}
+
+// token converts a LoginResponse received at the given time to an OAuthToken.
+func (resp *LoginResponse) token(received time.Time) *OAuthToken {
+	t := &OAuthToken{
+		AccessToken:  resp.Token,
+		RefreshToken: resp.RefreshToken,
+		TokenType:    resp.TokenType,
+	}
+	if resp.ExpiresIn > 0 {
+		t.Expiry = received.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return t
+}
+
+// Login implements TokenRefresher by logging in with the username and
+// password. If the account requires a verification code that the Creds don't
+// have, it is requested from MFAProvider.
+func (c *Creds) Login(ctx context.Context) (*OAuthToken, error) {
+	if err := c.ensureDeviceToken(); err != nil {
+		return nil, err
+	}
+
+	var resp LoginResponse
+	now := time.Now()
+	err := unauthenticatedPostAndDecode(ctx, c.httpClient(ctx), c.url(ctx, epLogin), c, &resp, nil)
+	if resp.Challenge != nil && resp.Challenge.ID != "" {
+		return c.loginWithChallenge(ctx, resp.Challenge)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.MFARequired {
+		return c.loginWithMFA(ctx, resp.MFAType)
+	}
+	return resp.token(now), nil
+}
+
+// refreshRequest is the body of a refresh token grant.
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+	ClientId     string `json:"client_id"`
+	GrantType    string `json:"grant_type"`
+}
+
+// Refresh implements TokenRefresher by exchanging the refresh token of tok
+// for a new token.
+func (c *Creds) Refresh(ctx context.Context, tok *OAuthToken) (*OAuthToken, error) {
+	if tok.RefreshToken == "" {
+		return nil, fmt.Errorf("robinhood: token has no refresh token")
+	}
+	req := refreshRequest{
+		RefreshToken: tok.RefreshToken,
+		ExpiresIn:    c.ExpiresIn,
+		Scope:        c.Scope,
+		ClientId:     c.ClientId,
+		GrantType:    "refresh_token",
+	}
+
+	var resp LoginResponse
+	now := time.Now()
+	err := unauthenticatedPostAndDecode(ctx, c.httpClient(ctx), c.url(ctx, epLogin), req, &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.token(now), nil
+}
+
+// getterRefresher adapts a TokenGetter to a TokenRefresher, so that a
+// Client can obtain a new token from it when its token is rejected.
+type getterRefresher struct {
+	TokenGetter
+}
+
+// Login implements TokenRefresher.
+func (g getterRefresher) Login(ctx context.Context) (*OAuthToken, error) {
+	tkn, err := getToken(ctx, g.TokenGetter)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthToken{AccessToken: tkn}, nil
+}
+
+// Refresh implements TokenRefresher. A TokenGetter can't refresh tokens, so
+// it always fails, and a new token is obtained with Login instead.
+func (g getterRefresher) Refresh(ctx context.Context, tok *OAuthToken) (*OAuthToken, error) {
+	return nil, fmt.Errorf("robinhood: %T can't refresh tokens", g.TokenGetter)
+}
+
+// authTransport sets the Authorization header of each request from the
+// Client's current token, renewing the token when it is about to expire or
+// is rejected.
+type authTransport struct {
+	mu        sync.Mutex
+	tok       *OAuthToken
+	refresher TokenRefresher
+	// client is the Client whose configuration renewals log in with.
+	client *Client
+
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, ErrLoggedOut
+	}
+
+	res, err := t.next.RoundTrip(authorize(req, tok))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The request can't be replayed.
+		return res, nil
+	}
+
+	// The token may have been revoked; renew it and try once more, unless
+	// the same token came back.
+	stale := tok
+	tok, err = t.renew(req.Context(), tok)
+	if err != nil || tok == nil || tok.AccessToken == stale.AccessToken {
+		return res, nil
+	}
+	res.Body.Close()
+
+	retry := authorize(req, tok)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(retry)
+}
+
+// token returns the current token, renewing it first if it is about to
+// expire.
+func (t *authTransport) token(ctx context.Context) (*OAuthToken, error) {
+	t.mu.Lock()
+	tok := t.tok
+	t.mu.Unlock()
+
+	if tok == nil || !tok.Expired(tokenRefreshLeeway) {
+		return tok, nil
+	}
+	return t.renew(ctx, tok)
+}
+
+// renew replaces stale with a new token, unless another request already has.
+// A refresh is attempted first, then a fresh login.
+func (t *authTransport) renew(ctx context.Context, stale *OAuthToken) (*OAuthToken, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.tok != stale || t.tok == nil {
+		return t.tok, nil
+	}
+
+	ctx = withLoggingInClient(ctx, t.client)
+	tok, err := t.refresher.Refresh(ctx, stale)
+	if err != nil {
+		tok, err = t.refresher.Login(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.tok = tok
+	return tok, nil
+}
+
+// logout discards the token, so that no further requests are made.
+func (t *authTransport) logout() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tok = nil
+}
+
+// current returns the token most recently used or obtained, or nil after
+// logout.
+func (t *authTransport) current() *OAuthToken {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tok
+}
+
+// authorize returns a copy of req authorized with tok.
+func authorize(req *http.Request, tok *OAuthToken) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	return req
+}
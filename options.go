@@ -0,0 +1,73 @@
+package robinhood
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// An Option configures a Client during Dial.
+type Option func(*Client)
+
+// WithBaseURL makes the Client resolve every API endpoint against base instead
+// of the live Robinhood API, e.g. to talk to a local stand-in server. Creds
+// without a BaseURL of their own log in against base too.
+func WithBaseURL(base string) Option {
+	return func(c *Client) {
+		c.baseURL = withSlash(base)
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to make requests. The bearer
+// token and any configured headers are still added to every request before it
+// is handed to rt.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// WithTimeout sets a time limit for each request made by the Client, including
+// reading the response body.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithHeader adds a header sent with every request made by the Client.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.header.Set("User-Agent", ua)
+	}
+}
+
+// withSlash returns base with exactly one trailing slash, so that relative
+// endpoints may be appended to it.
+func withSlash(base string) string {
+	return strings.TrimRight(base, "/") + "/"
+}
+
+// headerTransport adds a fixed set of headers to each request before passing
+// it to the next RoundTripper.
+type headerTransport struct {
+	header http.Header
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	for k, vs := range t.header {
+		req.Header[k] = vs
+	}
+	return t.next.RoundTrip(req)
+}
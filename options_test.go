@@ -0,0 +1,139 @@
+package robinhood_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+// recordingTransport records the requests made through it.
+type recordingTransport struct {
+	mu   sync.Mutex
+	reqs []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.reqs = append(t.reqs, req)
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// count returns the number of requests made to path.
+func (t *recordingTransport) count(path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, req := range t.reqs {
+		if req.URL.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestOptionsApplyToLogin(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+
+	rt := &recordingTransport{}
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"),
+		robinhood.WithBaseURL(s.URL),
+		robinhood.WithTransport(rt),
+		robinhood.WithUserAgent("robinhood-test"),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if c.Token == "" {
+		t.Fatal("no token")
+	}
+
+	if n := rt.count("/oauth2/token/"); n != 1 {
+		t.Fatalf("%d logins through the Client's transport, want 1", n)
+	}
+	for _, req := range rt.reqs {
+		if req.URL.Host != s.Listener.Addr().String() {
+			t.Errorf("request to %s, want the base URL", req.URL)
+		}
+		if ua := req.Header.Get("User-Agent"); ua != "robinhood-test" {
+			t.Errorf("User-Agent %q, want robinhood-test", ua)
+		}
+	}
+}
+
+func TestOptionsApplyToCachedLogin(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+
+	rt := &recordingTransport{}
+	cacher := &robinhood.CredsCacher{
+		Creds: robinhood.NewCreds("bob", "hunter2"),
+		Path:  t.TempDir() + "/token",
+	}
+	if _, err := robinhood.Dial(cacher, robinhood.WithBaseURL(s.URL), robinhood.WithTransport(rt)); err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if n := rt.count("/oauth2/token/"); n != 1 {
+		t.Fatalf("%d logins through the Client's transport, want 1", n)
+	}
+}
+
+func TestOptionsDoNotModifyCreds(t *testing.T) {
+	a := robinhoodtest.NewServer()
+	defer a.Close()
+	b := robinhoodtest.NewServer()
+	defer b.Close()
+	a.AddUser("bob", "hunter2", "")
+	b.AddUser("bob", "hunter2", "")
+	b.AddAccount(robinhood.Account{})
+
+	creds := robinhood.NewCreds("bob", "hunter2")
+	if _, err := robinhood.Dial(creds, robinhood.WithBaseURL(a.URL)); err != nil {
+		t.Fatalf("Dial A: %v", err)
+	}
+	if creds.BaseURL != "" || creds.HTTPClient != nil {
+		t.Errorf("Dial set BaseURL %q and HTTPClient %v on the Creds", creds.BaseURL, creds.HTTPClient)
+	}
+
+	// A later Client logs in with its own configuration, including when it
+	// renews a revoked token.
+	rt := &recordingTransport{}
+	c, err := robinhood.Dial(creds, robinhood.WithBaseURL(b.URL), robinhood.WithTransport(rt))
+	if err != nil {
+		t.Fatalf("Dial B: %v", err)
+	}
+	a.Close()
+	b.RevokeToken(c.AccessToken())
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts after revoke: %v", err)
+	}
+	if n := rt.count("/oauth2/token/"); n != 2 {
+		t.Errorf("%d logins through B's transport, want 2", n)
+	}
+}
+
+func TestSourceCredsUseEachClient(t *testing.T) {
+	a := robinhoodtest.NewServer()
+	defer a.Close()
+	b := robinhoodtest.NewServer()
+	defer b.Close()
+	a.AddUser("bob", "hunter2", "")
+	b.AddUser("bob", "hunter2", "")
+
+	t.Setenv("RHTEST_USERNAME", "bob")
+	t.Setenv("RHTEST_PASSWORD", "hunter2")
+	creds := &robinhood.SourceCreds{Source: &robinhood.EnvSource{Prefix: "RHTEST"}}
+	if _, err := robinhood.Dial(creds, robinhood.WithBaseURL(a.URL)); err != nil {
+		t.Fatalf("Dial A: %v", err)
+	}
+	a.Close()
+	if _, err := robinhood.Dial(creds, robinhood.WithBaseURL(b.URL)); err != nil {
+		t.Fatalf("Dial B: %v", err)
+	}
+}
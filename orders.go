@@ -1,6 +1,9 @@
 package robinhood
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type OrderRequest struct {
 	Account    string `json:"account"`
@@ -13,9 +16,9 @@ type OrderRequest struct {
 	// immediate or stop
 	Trigger Trigger `json:"trigger"`
 	// for use with limit
-	Price float64 `json:"price"`
+	Price Decimal `json:"price"`
 	// required when trigger equals stop
-	StopPrice float64 `json:"stop_price,omitempty"`
+	StopPrice Decimal `json:"stop_price,omitzero"`
 	Quantity  int     `json:"quantity"`
 	// buy or sell
 	Side Side `json:"side"`
@@ -23,6 +26,10 @@ type OrderRequest struct {
 	ExtendedHours          bool `json:"extended_hours"`
 	OverrideDayTradeChecks bool `json:"override_day_trade_checks"`
 	OverrideDtbpChecks     bool `json:"override_dtbp_checks"`
+	// Client-generated unique id for the order, e.g. a UUID. When set, the
+	// order is sent with it as an idempotency key so it may safely be
+	// retried.
+	RefID string `json:"ref_id,omitempty"`
 }
 type OrderType string
 
@@ -57,10 +64,19 @@ const (
 type Order struct {
 	Meta
 	Id                 string      `json:"id"`
+	Account            string      `json:"account"`
+	Instrument         string      `json:"instrument"`
+	Type               OrderType   `json:"type"`
+	TimeInForce        TimeInForce `json:"time_in_force"`
+	Trigger            Trigger     `json:"trigger"`
+	Side               Side        `json:"side"`
+	Price              Decimal     `json:"price"`
+	StopPrice          Decimal     `json:"stop_price"`
+	Quantity           Decimal     `json:"quantity"`
 	Executions         []Execution `json:"executions"`
-	Fees               float64     `json:"fees,string"`
+	Fees               Decimal     `json:"fees"`
 	Cancel             string      `json:"cancel"`
-	CumulativeQuantity float64     `json:"cumulative_quantity,string"`
+	CumulativeQuantity Decimal     `json:"cumulative_quantity"`
 	RejectReason       string      `json:"reject_reason"`
 	//queued, unconfirmed, confirmed, partially_filled, filled, rejected, canceled, or failed
 	State OrderState `json:"state"`
@@ -69,7 +85,7 @@ type Order struct {
 	ClientId               string  `json:"client_id"`
 	URL                    string  `json:"url"`
 	Position               string  `json:"position"`
-	AveragePrice           float64 `json:"average_price,string"`
+	AveragePrice           Decimal `json:"average_price"`
 	ExtendedHours          bool    `json:"extended_hours"`
 	OverrideDayTradeChecks bool    `json:"override_day_trade_checks"`
 	OverrideDtbpChecks     bool    `json:"override_dtbp_checks"`
@@ -79,8 +95,8 @@ type Order struct {
 
 type Execution struct {
 	Id             string
-	Price          float64   `json:"price,string"`
-	Quantity       float64   `json:"quantity,string"`
+	Price          Decimal   `json:"price"`
+	Quantity       Decimal   `json:"quantity"`
 	Timestamp      time.Time `json:"timestamp,string"`
 	SettlementDate string    `json:"settlement_date"`
 }
@@ -104,15 +120,28 @@ func (resp *Order) Details() string {
 
 // SendOrder will send an order to buy or sell
 func (c *Client) SendOrder(request *OrderRequest) (Order, error) {
+	return c.SendOrderContext(context.Background(), request)
+}
+
+// SendOrderContext is like SendOrder but the request is bound to ctx.
+func (c *Client) SendOrderContext(ctx context.Context, request *OrderRequest) (Order, error) {
+	if request.RefID != "" && idempotencyKey(ctx) == "" {
+		ctx = WithIdempotencyKey(ctx, request.RefID)
+	}
 	var response Order
-	err := c.PostAndDecode(epOrders, request, &response)
+	err := c.PostAndDecodeContext(ctx, c.url(epOrders), request, &response)
 	return response, err
 }
 
 // GetOrder returns the order with the given id
 func (c *Client) GetOrder(id string) (Order, error) {
+	return c.GetOrderContext(context.Background(), id)
+}
+
+// GetOrderContext is like GetOrder but the request is bound to ctx.
+func (c *Client) GetOrderContext(ctx context.Context, id string) (Order, error) {
 	var response Order
-	err := c.GetAndDecode(epOrders+id, &response)
+	err := c.GetAndDecodeContext(ctx, c.url(epOrders+id), &response)
 	return response, err
 }
 
@@ -123,7 +152,7 @@ type GetOrderRequest struct {
 }
 
 type GetOrderResponse struct {
-	Previous string `json:"prevoius"`
+	Previous string `json:"previous"`
 	Next     string `json:"next"`
 	Results  []Order
 	Detail   string `json:"detail"`
@@ -135,23 +164,13 @@ func (resp *GetOrderResponse) Details() string {
 
 // GetRecentOrders returns all recent orders for the instrument
 func (c *Client) GetRecentOrders(id *Instrument) ([]Order, error) {
-	var orders []Order
-	url := epOrders + "?instrument=" + id.URL
-	for {
-		var response GetOrderResponse
-		err := c.GetAndDecode(url, &response)
-		if err != nil {
-			return nil, err
-		}
-		orders = append(orders, response.Results...)
-		if response.Next == "" {
-			break
-		}
-		url = response.Next
-		time.Sleep(time.Second)
-	}
+	return c.GetRecentOrdersContext(context.Background(), id)
+}
 
-	return orders, nil
+// GetRecentOrdersContext is like GetRecentOrders but every page request is
+// bound to ctx.
+func (c *Client) GetRecentOrdersContext(ctx context.Context, id *Instrument) ([]Order, error) {
+	return NewPager[Order](c, c.url(epOrders)+"?instrument="+id.URL).All(ctx)
 }
 
 type CancelOrderResponse struct {
@@ -164,6 +183,11 @@ func (resp *CancelOrderResponse) Details() string {
 
 // CancelOrder will cancel the order with the given id
 func (c *Client) CancelOrder(id string) error {
+	return c.CancelOrderContext(context.Background(), id)
+}
+
+// CancelOrderContext is like CancelOrder but the request is bound to ctx.
+func (c *Client) CancelOrderContext(ctx context.Context, id string) error {
 	var r CancelOrderResponse
-	return c.PostAndDecode(epOrders+id+"/cancel/", struct{}{}, &r)
+	return c.PostAndDecodeContext(ctx, c.url(epOrders+id+"/cancel/"), struct{}{}, &r)
 }
@@ -0,0 +1,104 @@
+package robinhood
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMorePages is returned by a Pager asked for a page beyond either end of
+// the list.
+var ErrNoMorePages = errors.New("robinhood: no more pages")
+
+// A Page is a single page of results from a list endpoint.
+type Page[T any] struct {
+	Previous string `json:"previous"`
+	Next     string `json:"next"`
+	Results  []T    `json:"results"`
+	Detail   string `json:"detail"`
+}
+
+func (resp *Page[T]) Details() string {
+	return resp.Detail
+}
+
+// A Pager walks the pages of a list endpoint, following the API's next and
+// previous cursors.
+type Pager[T any] struct {
+	// MaxItems, if positive, is the most items the Pager will return in
+	// total. Once it is reached there are no further pages.
+	MaxItems int
+
+	c        *Client
+	next     string
+	previous string
+	seen     int
+}
+
+// NewPager returns a Pager whose first page is at url.
+func NewPager[T any](c *Client, url string) *Pager[T] {
+	return &Pager[T]{c: c, next: url}
+}
+
+// HasNext returns whether a call to Next will return a page.
+func (p *Pager[T]) HasNext() bool {
+	return p.next != "" && !p.full()
+}
+
+// HasPrevious returns whether a call to Previous will return a page.
+func (p *Pager[T]) HasPrevious() bool {
+	return p.previous != "" && !p.full()
+}
+
+// Next returns the results of the next page. ErrNoMorePages is returned once
+// the last page (or MaxItems) has been reached.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if !p.HasNext() {
+		return nil, ErrNoMorePages
+	}
+	return p.fetch(ctx, p.next)
+}
+
+// Previous returns the results of the page before the current one.
+// ErrNoMorePages is returned if the current page is the first, or MaxItems
+// has been reached.
+func (p *Pager[T]) Previous(ctx context.Context) ([]T, error) {
+	if !p.HasPrevious() {
+		return nil, ErrNoMorePages
+	}
+	return p.fetch(ctx, p.previous)
+}
+
+// All returns the results of every remaining page, up to MaxItems.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for p.HasNext() {
+		results, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, results...)
+	}
+	return all, nil
+}
+
+// fetch retrieves the page at url and moves the cursors to it.
+func (p *Pager[T]) fetch(ctx context.Context, url string) ([]T, error) {
+	var page Page[T]
+	err := p.c.GetAndDecodeContext(ctx, url, &page)
+	if err != nil {
+		return nil, err
+	}
+	p.next, p.previous = page.Next, page.Previous
+
+	results := page.Results
+	if p.MaxItems > 0 && p.seen+len(results) > p.MaxItems {
+		results = results[:p.MaxItems-p.seen]
+	}
+	p.seen += len(results)
+	return results, nil
+}
+
+// full returns whether the Pager has returned MaxItems items.
+func (p *Pager[T]) full() bool {
+	return p.MaxItems > 0 && p.seen >= p.MaxItems
+}
@@ -1,24 +1,26 @@
 package robinhood
 
+import "context"
+
 type Portfolio struct {
 	Account                                string  `json:"account"`
-	AdjustedEquityPreviousClose            float64 `json:"adjusted_equity_previous_close,string"`
-	Equity                                 float64 `json:"equity,string"`
-	EquityPreviousClose                    float64 `json:"equity_previous_close,string"`
-	ExcessMaintenance                      float64 `json:"excess_maintenance,string"`
-	ExcessMaintenanceWithUnclearedDeposits float64 `json:"excess_maintenance_with_uncleared_deposits,string"`
-	ExcessMargin                           float64 `json:"excess_margin,string"`
-	ExcessMarginWithUnclearedDeposits      float64 `json:"excess_margin_with_uncleared_deposits,string"`
-	ExtendedHoursEquity                    float64 `json:"extended_hours_equity,string"`
-	ExtendedHoursMarketValue               float64 `json:"extended_hours_market_value,string"`
-	LastCoreEquity                         float64 `json:"last_core_equity,string"`
-	LastCoreMarketValue                    float64 `json:"last_core_market_value,string"`
-	MarketValue                            float64 `json:"market_value,string"`
+	AdjustedEquityPreviousClose            Decimal `json:"adjusted_equity_previous_close"`
+	Equity                                 Decimal `json:"equity"`
+	EquityPreviousClose                    Decimal `json:"equity_previous_close"`
+	ExcessMaintenance                      Decimal `json:"excess_maintenance"`
+	ExcessMaintenanceWithUnclearedDeposits Decimal `json:"excess_maintenance_with_uncleared_deposits"`
+	ExcessMargin                           Decimal `json:"excess_margin"`
+	ExcessMarginWithUnclearedDeposits      Decimal `json:"excess_margin_with_uncleared_deposits"`
+	ExtendedHoursEquity                    Decimal `json:"extended_hours_equity"`
+	ExtendedHoursMarketValue               Decimal `json:"extended_hours_market_value"`
+	LastCoreEquity                         Decimal `json:"last_core_equity"`
+	LastCoreMarketValue                    Decimal `json:"last_core_market_value"`
+	MarketValue                            Decimal `json:"market_value"`
 	StartDate                              string  `json:"start_date"`
-	UnwithdrawableDeposits                 float64 `json:"unwithdrawable_deposits,string"`
-	UnwithdrawableGrants                   float64 `json:"unwithdrawable_grants,string"`
+	UnwithdrawableDeposits                 Decimal `json:"unwithdrawable_deposits"`
+	UnwithdrawableGrants                   Decimal `json:"unwithdrawable_grants"`
 	URL                                    string  `json:"url"`
-	WithdrawableAmount                     float64 `json:"withdrawable_amount,string"`
+	WithdrawableAmount                     Decimal `json:"withdrawable_amount"`
 }
 
 type GetPortfolioResponse struct {
@@ -33,7 +35,26 @@ func (resp *GetPortfolioResponse) Details() string {
 // GetPortfolios returns all the portfolios associated with a client's
 // credentials and accounts
 func (c *Client) GetPortfolios() ([]Portfolio, error) {
-	var p GetPortfolioResponse
-	err := c.GetAndDecode(epPortfolios, &p)
-	return p.Results, err
+	return c.GetPortfoliosContext(context.Background())
+}
+
+// GetPortfoliosContext is like GetPortfolios but the request is bound to ctx.
+func (c *Client) GetPortfoliosContext(ctx context.Context) ([]Portfolio, error) {
+	return NewPager[Portfolio](c, c.url(epPortfolios)).All(ctx)
+}
+
+func (resp *Portfolio) Details() string {
+	return ""
+}
+
+// GetPortfolio returns the portfolio of an account.
+func (c *Client) GetPortfolio(a Account) (Portfolio, error) {
+	return c.GetPortfolioContext(context.Background(), a)
+}
+
+// GetPortfolioContext is like GetPortfolio but the request is bound to ctx.
+func (c *Client) GetPortfolioContext(ctx context.Context, a Account) (Portfolio, error) {
+	var p Portfolio
+	err := c.GetAndDecodeContext(ctx, a.Portfolio, &p)
+	return p, err
 }
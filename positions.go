@@ -1,15 +1,17 @@
 package robinhood
 
+import "context"
+
 type Position struct {
 	Meta
 	Account                 string  `json:"account"`
-	AverageBuyPrice         float64 `json:"average_buy_price,string"`
+	AverageBuyPrice         Decimal `json:"average_buy_price"`
 	Instrument              string  `json:"instrument"`
-	IntradayAverageBuyPrice float64 `json:"intraday_average_buy_price,string"`
-	IntradayQuantity        float64 `json:"intraday_quantity,string"`
-	Quantity                float64 `json:"quantity,string"`
-	SharesHeldForBuys       float64 `json:"shares_held_for_buys,string"`
-	SharesHeldForSells      float64 `json:"shares_held_for_sells,string"`
+	IntradayAverageBuyPrice Decimal `json:"intraday_average_buy_price"`
+	IntradayQuantity        Decimal `json:"intraday_quantity"`
+	Quantity                Decimal `json:"quantity"`
+	SharesHeldForBuys       Decimal `json:"shares_held_for_buys"`
+	SharesHeldForSells      Decimal `json:"shares_held_for_sells"`
 }
 
 type GetPositionsResponse struct {
@@ -23,7 +25,10 @@ func (resp *GetPositionsResponse) Details() string {
 
 // GetPositions returns all the positions associated with an account.
 func (c Client) GetPositions(a Account) ([]Position, error) {
-	var r GetPositionsResponse
-	err := c.GetAndDecode(a.Positions, &r)
-	return r.Results, err
+	return c.GetPositionsContext(context.Background(), a)
+}
+
+// GetPositionsContext is like GetPositions but the request is bound to ctx.
+func (c Client) GetPositionsContext(ctx context.Context, a Account) ([]Position, error) {
+	return NewPager[Position](&c, a.Positions).All(ctx)
 }
@@ -0,0 +1,147 @@
+package robinhood
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileExt is the extension of profile token files in a ProfileStore.
+const profileExt = ".token"
+
+// A ProfileStore keeps the cached tokens of several named logins, or
+// profiles, in one directory, so that one host can run Clients for several
+// accounts. Each profile is a CredsCacher file named after the profile, with
+// the same locking, so processes sharing the directory don't log the same
+// profile in twice or overwrite each other's tokens.
+type ProfileStore struct {
+	// Dir is the directory the profiles are kept in.
+	Dir string
+
+	// Creds returns the credentials to log the named profile in with when
+	// its cached token can't be used. If nil, each profile reads its
+	// credentials lazily from environment variables prefixed with
+	// ROBINHOOD_ and the upper-cased profile name, e.g.
+	// ROBINHOOD_TRADING_BOT_USERNAME for the profile "trading-bot".
+	Creds func(name string) TokenGetter
+
+	// Key and Passphrase encrypt the profiles' tokens; see CredsCacher.
+	Key        []byte
+	Passphrase string
+}
+
+// NewProfileStore returns a ProfileStore in dir, creating the directory if
+// needed.
+func NewProfileStore(dir string) (*ProfileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating profile directory: %s", err)
+	}
+	return &ProfileStore{Dir: dir}, nil
+}
+
+// Cacher returns the CredsCacher for the named profile.
+func (s *ProfileStore) Cacher(name string) (*CredsCacher, error) {
+	if err := validProfileName(name); err != nil {
+		return nil, err
+	}
+
+	var creds TokenGetter
+	if s.Creds != nil {
+		creds = s.Creds(name)
+	} else {
+		prefix := "ROBINHOOD_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		creds = &SourceCreds{Source: &EnvSource{Prefix: prefix}}
+	}
+	if creds == nil {
+		return nil, fmt.Errorf("robinhood: no credentials for profile %q", name)
+	}
+
+	return &CredsCacher{
+		Creds:      creds,
+		Path:       s.path(name),
+		Key:        s.Key,
+		Passphrase: s.Passphrase,
+	}, nil
+}
+
+// DialProfile returns a Client for the named profile, logging it in if its
+// cached token can't be used.
+func (s *ProfileStore) DialProfile(name string, opts ...Option) (*Client, error) {
+	return s.DialProfileContext(context.Background(), name, opts...)
+}
+
+// DialProfileContext is like DialProfile but ctx bounds logging in.
+func (s *ProfileStore) DialProfileContext(ctx context.Context, name string, opts ...Option) (*Client, error) {
+	c, err := s.Cacher(name)
+	if err != nil {
+		return nil, err
+	}
+	return DialContext(ctx, c, opts...)
+}
+
+// List returns the names of the profiles that have been logged in, sorted.
+func (s *ProfileStore) List() ([]string, error) {
+	fis, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fi := range fis {
+		name := strings.TrimSuffix(fi.Name(), profileExt)
+		if fi.Mode().IsRegular() && name != fi.Name() && validProfileName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Remove deletes the named profile's cached token, waiting for any process
+// using it to finish. It doesn't revoke the token; use Client.Logout for
+// that.
+func (s *ProfileStore) Remove(name string) error {
+	if err := validProfileName(name); err != nil {
+		return err
+	}
+	c := &CredsCacher{Path: s.path(name)}
+	unlock, err := c.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = os.Remove(c.Path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("robinhood: no profile %q", name)
+	}
+	return err
+}
+
+// path returns the token file of the named profile.
+func (s *ProfileStore) path(name string) string {
+	return filepath.Join(s.Dir, name+profileExt)
+}
+
+// validProfileName returns an error unless name is usable as a profile name:
+// letters, digits, '-', '_' and '.', not starting with '.'.
+func validProfileName(name string) error {
+	if name == "" || len(name) > 64 || name[0] == '.' {
+		return fmt.Errorf("robinhood: invalid profile name %q", name)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return fmt.Errorf("robinhood: invalid profile name %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package robinhood_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+func TestProfileStore(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("alice", "secret", "")
+	s.AddUser("bob", "hunter2", "")
+
+	dir := filepath.Join(t.TempDir(), "profiles")
+	store, err := robinhood.NewProfileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords := map[string][2]string{"alice": {"alice", "secret"}, "bob.paper": {"bob", "hunter2"}}
+	store.Creds = func(name string) robinhood.TokenGetter {
+		p, ok := passwords[name]
+		if !ok {
+			return nil
+		}
+		return robinhood.NewCreds(p[0], p[1])
+	}
+
+	rt := &recordingTransport{}
+	dial := func(name string) *robinhood.Client {
+		t.Helper()
+		c, err := store.DialProfile(name, robinhood.WithBaseURL(s.URL), robinhood.WithTransport(rt))
+		if err != nil {
+			t.Fatalf("DialProfile(%q): %v", name, err)
+		}
+		return c
+	}
+	alice, bob := dial("alice"), dial("bob.paper")
+	if alice.AccessToken() == bob.AccessToken() {
+		t.Error("profiles share a token")
+	}
+	if again := dial("alice"); again.AccessToken() != alice.AccessToken() {
+		t.Error("profile token was not reused")
+	}
+	if n := rt.count("/oauth2/token/"); n != 2 {
+		t.Errorf("%d logins, want one per profile", n)
+	}
+	if _, err := store.DialProfile("carol", robinhood.WithBaseURL(s.URL)); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("DialProfile without credentials: got %v", err)
+	}
+
+	// Only token files of valid names are profiles.
+	for _, name := range []string{"junk.token.tmp123", ".hidden.token", "notes.txt", "alice.token.lock"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.token"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	list := func() []string {
+		t.Helper()
+		names, err := store.List()
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		return names
+	}
+	if got, want := list(), []string{"alice", "bob.paper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %q, want %q", got, want)
+	}
+
+	if err := store.Remove("alice"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if got, want := list(), []string{"bob.paper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List after Remove = %q, want %q", got, want)
+	}
+	if err := store.Remove("alice"); err == nil {
+		t.Error("removing a missing profile succeeded")
+	}
+
+	for _, name := range []string{"", "../x", "..", ".hidden", "a/b", `a\b`, "a b", "bob.paper/../alice", strings.Repeat("a", 65)} {
+		if _, err := store.Cacher(name); err == nil {
+			t.Errorf("Cacher(%q) succeeded", name)
+		}
+		if _, err := store.DialProfile(name, robinhood.WithBaseURL(s.URL)); err == nil {
+			t.Errorf("DialProfile(%q) succeeded", name)
+		}
+		if err := store.Remove(name); err == nil || !strings.Contains(err.Error(), "invalid profile name") {
+			t.Errorf("Remove(%q): got %v, want invalid profile name", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "x.token")); !os.IsNotExist(err) {
+		t.Error("a profile was written outside the store")
+	}
+
+	if names, err := (&robinhood.ProfileStore{Dir: filepath.Join(dir, "missing")}).List(); err != nil || names != nil {
+		t.Errorf("List of a missing directory: got %q, %v, want none", names, err)
+	}
+}
+
+func TestProfileStoreEnvCreds(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bot", "hunter2", "")
+
+	t.Setenv("ROBINHOOD_TRADING_BOT_USERNAME", "bot")
+	t.Setenv("ROBINHOOD_TRADING_BOT_PASSWORD", "hunter2")
+	store := &robinhood.ProfileStore{Dir: t.TempDir()}
+	if _, err := store.DialProfile("trading-bot", robinhood.WithBaseURL(s.URL)); err != nil {
+		t.Fatalf("DialProfile: %v", err)
+	}
+	if _, err := store.DialProfile("other", robinhood.WithBaseURL(s.URL)); err == nil {
+		t.Error("DialProfile without environment credentials succeeded")
+	}
+}
@@ -1,20 +1,21 @@
 package robinhood
 
 import (
+	"context"
 	"strings"
 )
 
 // A Quote is a representation of the data returned by the Robinhood API for
 // current stock quotes
 type Quote struct {
-	AdjustedPreviousClose       float64 `json:"adjusted_previous_close,string"`
-	AskPrice                    float64 `json:"ask_price,string"`
+	AdjustedPreviousClose       Decimal `json:"adjusted_previous_close"`
+	AskPrice                    Decimal `json:"ask_price"`
 	AskSize                     int     `json:"ask_size"`
-	BidPrice                    float64 `json:"bid_price,string"`
+	BidPrice                    Decimal `json:"bid_price"`
 	BidSize                     int     `json:"bid_size"`
-	LastExtendedHoursTradePrice float64 `json:"last_extended_hours_trade_price,string"`
-	LastTradePrice              float64 `json:"last_trade_price,string"`
-	PreviousClose               float64 `json:"previous_close,string"`
+	LastExtendedHoursTradePrice Decimal `json:"last_extended_hours_trade_price"`
+	LastTradePrice              Decimal `json:"last_trade_price"`
+	PreviousClose               Decimal `json:"previous_close"`
 	PreviousCloseDate           string  `json:"previous_close_date"`
 	Symbol                      string  `json:"symbol"`
 	TradingHalted               bool    `json:"trading_halted"`
@@ -32,14 +33,19 @@ func (resp *GetQuotesResponse) Details() string {
 
 // GetQuote returns all the latest stock quotes for the list of stocks provided
 func (c Client) GetQuote(stocks ...string) ([]Quote, error) {
-	url := epQuotes + "?symbols=" + strings.Join(stocks, ",")
+	return c.GetQuoteContext(context.Background(), stocks...)
+}
+
+// GetQuoteContext is like GetQuote but the request is bound to ctx.
+func (c Client) GetQuoteContext(ctx context.Context, stocks ...string) ([]Quote, error) {
+	url := c.url(epQuotes) + "?symbols=" + strings.Join(stocks, ",")
 	var r GetQuotesResponse
-	err := c.GetAndDecode(url, &r)
+	err := c.GetAndDecodeContext(ctx, url, &r)
 	return r.Results, err
 }
 
 // Price returns the proper stock price even after hours
-func (q Quote) Price() float64 {
+func (q Quote) Price() Decimal {
 	if IsRegularTradingTime() {
 		return q.LastTradePrice
 	}
@@ -0,0 +1,86 @@
+package robinhood
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimit and DefaultRateBurst configure the limiter shared by all
+// requests of a Client unless overridden with WithRateLimit.
+const (
+	DefaultRateLimit rate.Limit = 2
+	DefaultRateBurst            = 5
+)
+
+// WithRateLimit sets the rate (in requests per second) and burst of the token
+// bucket shared by every request the Client makes, across all goroutines,
+// including logins, token refreshes, retries and revocations. Pass rate.Inf
+// to disable client-side throttling.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(c *Client) {
+		c.limiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// WithEndpointRateLimit adds a further limit on requests to one endpoint
+// family, named by the first path segment of its URL relative to the base URL
+// (e.g. "orders" or "quotes"). Requests to that family wait on both this
+// limiter and the Client-wide one.
+func WithEndpointRateLimit(family string, r rate.Limit, burst int) Option {
+	return func(c *Client) {
+		if c.familyLimiters == nil {
+			c.familyLimiters = map[string]*rate.Limiter{}
+		}
+		c.familyLimiters[strings.Trim(family, "/")] = rate.NewLimiter(r, burst)
+	}
+}
+
+// wait blocks until the Client's limiters allow a request to u, or ctx is
+// done.
+func (c *Client) wait(ctx context.Context, u *url.URL) error {
+	if l, ok := c.familyLimiters[c.endpointFamily(u)]; ok {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
+
+// endpointFamily returns the first segment of u's path relative to the
+// Client's base URL.
+func (c *Client) endpointFamily(u *url.URL) string {
+	p := u.Path
+	if base, err := url.Parse(c.baseURL); err == nil && strings.HasPrefix(p, base.Path) {
+		p = p[len(base.Path):]
+	}
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		p = p[:i]
+	}
+	return p
+}
+
+// limitTransport makes each request wait for the Client's limiters before
+// passing it to the next RoundTripper.
+type limitTransport struct {
+	client *Client
+	next   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.client.wait(req.Context(), req.URL); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,100 @@
+package robinhood_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitAppliesToLogin(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	rt := &recordingTransport{}
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"), robinhood.WithBaseURL(s.URL), robinhood.WithTransport(rt), robinhood.WithRateLimit(rate.Every(time.Hour), 1))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+
+	// The login used up the burst.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetAccountsContext(ctx); err == nil {
+		t.Error("GetAccounts after login was not throttled")
+	}
+	if n := len(rt.reqs); n != 1 {
+		t.Errorf("%d requests sent, want only the login", n)
+	}
+}
+
+func TestRateLimitAppliesToReplay(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	const interval = 50 * time.Millisecond
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"), robinhood.WithBaseURL(s.URL), robinhood.WithRateLimit(rate.Every(interval), 1))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	s.RevokeToken(c.AccessToken())
+	time.Sleep(interval)
+
+	// The rejected request, the login and the replay each wait their turn.
+	start := time.Now()
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if d := time.Since(start); d < 2*interval-5*time.Millisecond {
+		t.Errorf("three requests took %v, want at least %v", d, 2*interval)
+	}
+}
+
+// prefixTransport serves a base URL with a path prefix from a server at the
+// root, by stripping the prefix.
+type prefixTransport struct {
+	prefix string
+}
+
+func (t prefixTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, t.prefix)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestEndpointRateLimitWithBasePath(t *testing.T) {
+	s := robinhoodtest.NewServer()
+	defer s.Close()
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	c, err := robinhood.Dial(robinhood.NewCreds("bob", "hunter2"),
+		robinhood.WithBaseURL(s.URL+"/api"),
+		robinhood.WithTransport(prefixTransport{"/api"}),
+		robinhood.WithRateLimit(rate.Inf, 0),
+		robinhood.WithEndpointRateLimit("accounts", rate.Every(time.Hour), 1),
+	)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetAccountsContext(ctx); err == nil {
+		t.Error("second GetAccounts was not throttled by the accounts limit")
+	}
+	if _, err := c.GetPortfolios(); err != nil {
+		t.Errorf("GetPortfolios was throttled by the accounts limit: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package robinhood
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy controls how a Client retries requests that fail because of
+// throttling (429), server errors (5xx) or network errors. GET requests are
+// always eligible; POST requests only if their context carries an idempotency
+// key (see WithIdempotencyKey).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a request,
+	// including the first. Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with each
+	// subsequent retry, with jitter applied.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed delay between attempts. A Retry-After
+	// header from the server is honored even if it exceeds MaxDelay.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy for batch jobs. Clients do
+// not retry unless configured to with WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// WithRetryPolicy makes the Client retry failed requests according to p.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// delay returns how long to wait after the given (1-based) failed attempt.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	if d, ok := retryAfter(err); ok {
+		return d
+	}
+
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Wait somewhere between half and all of d so that concurrent clients
+	// don't retry in lockstep.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter returns the delay requested by a Retry-After header on err, if
+// err is an APIError that has one.
+func retryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0, false
+	}
+	v := apiErr.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// shouldRetry returns whether err is worth retrying.
+func shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrLoggedOut) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// Anything else came from the transport or from decoding; only the
+	// former is transient.
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+// A RetryError is returned when a request failed after more than one attempt.
+// It wraps the error from the final attempt.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements error.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+}
+
+// Unwrap returns the error from the final attempt.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey returns a context carrying an idempotency key. POST
+// requests made with it send the key in an Idempotency-Key header and may be
+// retried by the Client's RetryPolicy.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// idempotencyKey returns the idempotency key carried by ctx, if any.
+func idempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key
+}
@@ -0,0 +1,199 @@
+package robinhoodtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"astuart.co/go-robinhood"
+)
+
+// AddACHRelationship links a bank account to the account at rel.Account, or
+// to the first account if it is empty, filling in its id and URLs. The
+// stored relationship is returned.
+func (s *Server) AddACHRelationship(rel robinhood.ACHRelationship) robinhood.ACHRelationship {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rel.Account == "" && len(s.accounts) > 0 {
+		rel.Account = s.accounts[0].URL
+	}
+	rel.ID = s.newID()
+	rel.URL = s.URL + "/ach/relationships/" + rel.ID + "/"
+	rel.Unlink = rel.URL + "unlink/"
+	if rel.State == "" {
+		rel.State = "approved"
+		rel.Verified = true
+	}
+	if rel.CreatedAt.IsZero() {
+		rel.CreatedAt = time.Now()
+	}
+	s.achRelationships = append(s.achRelationships, &rel)
+	return rel
+}
+
+// Transfers returns the ACH transfers made so far, in the order they were
+// made.
+func (s *Server) Transfers() []robinhood.ACHTransfer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfers := make([]robinhood.ACHTransfer, len(s.transfers))
+	for i, t := range s.transfers {
+		transfers[i] = t.ACHTransfer
+	}
+	return transfers
+}
+
+// SetTransferState moves the ACH transfer with the given id to state. When
+// a transfer completes, its amount is added to or taken from the account's
+// cash.
+func (s *Server) SetTransferState(id string, state robinhood.TransferState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.transfer(id)
+	if t == nil {
+		return fmt.Errorf("robinhoodtest: no transfer %s", id)
+	}
+	if t.State.Settled() {
+		return fmt.Errorf("robinhoodtest: transfer %s is already %s", id, t.State)
+	}
+	t.State = state
+	t.UpdatedAt = time.Now()
+	if state.Settled() {
+		t.Cancel = ""
+	}
+
+	if state == robinhood.TransferState_Completed {
+		for _, a := range s.accounts {
+			if a.URL != t.account {
+				continue
+			}
+			amount := t.Amount
+			if t.Direction == robinhood.TransferDirection_Withdraw {
+				amount = amount.Neg()
+			}
+			a.Cash = a.Cash.Add(amount)
+			a.CashAvailableForWithdrawal = a.CashAvailableForWithdrawal.Add(amount)
+		}
+	}
+	return nil
+}
+
+// transfer is an ACH transfer as stored by the Server.
+type transfer struct {
+	robinhood.ACHTransfer
+	account string
+}
+
+func (s *Server) transfer(id string) *transfer {
+	for _, t := range s.transfers {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleACHRelationships(w http.ResponseWriter, r *http.Request) {
+	results := make([]interface{}, len(s.achRelationships))
+	for i, rel := range s.achRelationships {
+		results[i] = rel
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleTransfers(w http.ResponseWriter, r *http.Request) {
+	// Most recent first, as the API returns them.
+	results := make([]interface{}, len(s.transfers))
+	for i, t := range s.transfers {
+		results[len(results)-1-i] = t.ACHTransfer
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	t := s.transfer(r.PathValue("id"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, t.ACHTransfer)
+}
+
+func (s *Server) handleSendTransfer(w http.ResponseWriter, r *http.Request) {
+	var req robinhood.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDetail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var rel *robinhood.ACHRelationship
+	for _, candidate := range s.achRelationships {
+		if candidate.URL == req.ACHRelationship {
+			rel = candidate
+		}
+	}
+	fieldErrs := map[string][]string{}
+	if rel == nil {
+		fieldErrs["ach_relationship"] = []string{"Invalid hyperlink - Object does not exist."}
+	}
+	if req.Amount.Sign() <= 0 {
+		fieldErrs["amount"] = []string{"Ensure this value is greater than 0."}
+	}
+	if req.Direction != robinhood.TransferDirection_Deposit && req.Direction != robinhood.TransferDirection_Withdraw {
+		fieldErrs["direction"] = []string{fmt.Sprintf("%q is not a valid choice.", req.Direction)}
+	}
+	if len(fieldErrs) > 0 {
+		writeJSON(w, http.StatusBadRequest, fieldErrs)
+		return
+	}
+
+	if req.Direction == robinhood.TransferDirection_Withdraw {
+		for _, a := range s.accounts {
+			if a.URL == rel.Account && a.CashAvailableForWithdrawal.Cmp(req.Amount) < 0 {
+				writeJSON(w, http.StatusBadRequest, map[string][]string{
+					"non_field_errors": {"Insufficient funds to withdraw."},
+				})
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	id := s.newID()
+	t := &transfer{
+		ACHTransfer: robinhood.ACHTransfer{
+			ID:              id,
+			ACHRelationship: rel.URL,
+			Amount:          req.Amount,
+			Direction:       req.Direction,
+			State:           robinhood.TransferState_Pending,
+			Cancel:          s.URL + "/ach/transfers/" + id + "/cancel/",
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			URL:             s.URL + "/ach/transfers/" + id + "/",
+		},
+		account: rel.Account,
+	}
+	s.transfers = append(s.transfers, t)
+	writeJSON(w, http.StatusCreated, t.ACHTransfer)
+}
+
+func (s *Server) handleCancelTransfer(w http.ResponseWriter, r *http.Request) {
+	t := s.transfer(r.PathValue("id"))
+	if t == nil {
+		writeDetail(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	if t.Cancel == "" || (t.State != robinhood.TransferState_Pending && t.State != robinhood.TransferState_Requested) {
+		writeDetail(w, http.StatusBadRequest, "Transfer cannot be cancelled.")
+		return
+	}
+	t.State = robinhood.TransferState_Cancelled
+	t.Cancel = ""
+	t.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, t.ACHTransfer)
+}
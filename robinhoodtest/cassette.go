@@ -0,0 +1,333 @@
+package robinhoodtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Mode selects whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves responses from a cassette file without making any
+	// real requests.
+	ModeReplay Mode = iota
+	// ModeRecord passes requests through to a real transport and records
+	// them, to be written to a cassette file by Save.
+	ModeRecord
+)
+
+// An Interaction is a recorded request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// A RecordedRequest is the part of a request used to match it on replay.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// A RecordedResponse is a response as stored in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// A Recorder is an http.RoundTripper that records the interactions made
+// through it to a cassette file, or replays them from one. Use it with
+// robinhood.WithTransport to snapshot a Client's behaviour and run it again
+// offline.
+//
+// Requests are matched on method, path and query, so the same cassette
+// replays against any base URL. Recorded interactions are replayed in order;
+// once every match has been used, the last one is served again, so polling
+// loops replay too.
+//
+// Before a cassette is saved, tokens, passwords and MFA codes are redacted
+// and every account number is replaced by a placeholder.
+type Recorder struct {
+	// Path is the cassette file.
+	Path string
+	// Mode selects recording or replaying.
+	Mode Mode
+	// Next is the transport real requests are made with when recording. If
+	// nil, http.DefaultTransport is used.
+	Next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a Recorder using the cassette at path. In ModeReplay
+// the cassette is loaded immediately.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Path: path, Mode: mode}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &r.interactions); err != nil {
+		return nil, fmt.Errorf("robinhoodtest: reading cassette %s: %s", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	rec := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  sortedQuery(req.URL.RawQuery),
+		Body:   string(body),
+	}
+
+	if r.Mode == ModeReplay {
+		return r.replay(req, rec)
+	}
+	return r.record(req, rec, body)
+}
+
+func (r *Recorder) replay(req *http.Request, rec RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != rec.Method || in.Request.Path != rec.Path || in.Request.Query != rec.Query {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("robinhoodtest: no recorded interaction for %s %s", req.Method, req.URL)
+	}
+	r.used[last] = true
+
+	res := r.interactions[last].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode:    res.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        res.Header.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request, rec RecordedRequest, body []byte) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	header := res.Header.Clone()
+	header.Del("Set-Cookie")
+	// Scrubbing may change the body's length.
+	header.Del("Content-Length")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: rec,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     header,
+			Body:       string(resBody),
+		},
+	})
+	return res, nil
+}
+
+// Save scrubs the recorded interactions and writes them to the cassette file.
+// It does nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.Mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	interactions := scrub(r.interactions)
+	r.mu.Unlock()
+
+	bs, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, append(bs, '\n'), 0640)
+}
+
+// scrubbedFields are the JSON fields whose values are redacted in cassettes.
+// Keep it in sync with redactedFields in the robinhood package's log.go.
+var scrubbedFields = map[string]bool{
+	"password":      true,
+	"mfa_code":      true,
+	"access_token":  true,
+	"refresh_token": true,
+	"device_token":  true,
+	"token":         true,
+	"response":      true,
+}
+
+// scrub returns a copy of interactions with secrets redacted and account
+// numbers replaced by placeholders.
+func scrub(interactions []Interaction) []Interaction {
+	accounts := map[string]string{}
+	var numbers []string
+	for _, in := range interactions {
+		for _, body := range []string{in.Request.Body, in.Response.Body} {
+			for _, n := range accountNumbers(body) {
+				if _, ok := accounts[n]; !ok {
+					accounts[n] = fmt.Sprintf("ACCOUNT%04d", len(accounts)+1)
+					numbers = append(numbers, n)
+				}
+			}
+		}
+	}
+	// Replace longer numbers first, in case one contains another.
+	sort.Slice(numbers, func(i, j int) bool { return len(numbers[i]) > len(numbers[j]) })
+	replace := func(s string) string {
+		for _, n := range numbers {
+			s = strings.Replace(s, n, accounts[n], -1)
+		}
+		return s
+	}
+
+	out := make([]Interaction, len(interactions))
+	for i, in := range interactions {
+		out[i] = Interaction{
+			Request: RecordedRequest{
+				Method: in.Request.Method,
+				Path:   replace(in.Request.Path),
+				Query:  replace(in.Request.Query),
+				Body:   replace(redactJSON(in.Request.Body)),
+			},
+			Response: RecordedResponse{
+				StatusCode: in.Response.StatusCode,
+				Header:     in.Response.Header,
+				Body:       replace(redactJSON(in.Response.Body)),
+			},
+		}
+	}
+	return out
+}
+
+// accountNumbers returns the values of any account_number fields in a JSON
+// body.
+func accountNumbers(body string) []string {
+	var v interface{}
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return nil
+	}
+	var numbers []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, val := range v {
+				if n, ok := val.(string); ok && k == "account_number" && n != "" {
+					numbers = append(numbers, n)
+				} else {
+					walk(val)
+				}
+			}
+		case []interface{}:
+			for _, val := range v {
+				walk(val)
+			}
+		}
+	}
+	walk(v)
+	return numbers
+}
+
+// redactJSON returns body with the values of scrubbedFields redacted, if it
+// is JSON.
+func redactJSON(body string) string {
+	var v interface{}
+	if json.Unmarshal([]byte(body), &v) != nil {
+		return body
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, val := range v {
+				if _, ok := val.(string); ok && scrubbedFields[k] {
+					v[k] = "REDACTED"
+				} else {
+					walk(val)
+				}
+			}
+		case []interface{}:
+			for _, val := range v {
+				walk(val)
+			}
+		}
+	}
+	walk(v)
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return string(bs)
+}
+
+// sortedQuery returns the raw query with its parameters in a canonical order.
+func sortedQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	params := strings.Split(raw, "&")
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,121 @@
+package robinhoodtest_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	acct := s.AddAccount(robinhood.Account{})
+
+	rec, err := robinhoodtest.NewRecorder(path, robinhoodtest.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := dial(t, s, robinhood.NewCreds("bob", "hunter2"), robinhood.WithTransport(rec))
+	accts, err := c.GetAccounts()
+	if err != nil || len(accts) != 1 {
+		t.Fatalf("GetAccounts: got %v, %v, want 1 account", accts, err)
+	}
+	if _, err := c.GetPortfolio(accts[0]); err != nil {
+		t.Fatalf("GetPortfolio: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	s.Close()
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", acct.AccountNumber, c.AccessToken()} {
+		if strings.Contains(string(bs), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	// Replay offline, against a base URL that doesn't exist.
+	rec, err = robinhoodtest.NewRecorder(path, robinhoodtest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = robinhood.Dial(robinhood.NewCreds("bob", "hunter2"), robinhood.WithBaseURL("http://replay.invalid/"), robinhood.WithTransport(rec))
+	if err != nil {
+		t.Fatalf("Dial on replay: %v", err)
+	}
+	accts, err = c.GetAccounts()
+	if err != nil || len(accts) != 1 {
+		t.Fatalf("GetAccounts on replay: got %v, %v, want 1 account", accts, err)
+	}
+	a := accts[0]
+	if a.AccountNumber != "ACCOUNT0001" || !strings.HasSuffix(a.URL, "/accounts/ACCOUNT0001/") || !strings.HasSuffix(a.Portfolio, "/portfolios/ACCOUNT0001/") {
+		t.Errorf("replayed account %s has URLs %s and %s, want them for ACCOUNT0001", a.AccountNumber, a.URL, a.Portfolio)
+	}
+	if _, err := c.GetPortfolio(a); err != nil {
+		t.Errorf("GetPortfolio on replay: %v", err)
+	}
+	if _, err := c.GetAccount("5RH99999"); err == nil {
+		t.Error("GetAccount of an unrecorded account succeeded on replay")
+	}
+}
+
+func TestReplayMatchesQueryInAnyOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+	token := s.IssueToken("bob")
+
+	get := func(rt http.RoundTripper, url string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return rt.RoundTrip(req)
+	}
+
+	rec, err := robinhoodtest.NewRecorder(path, robinhoodtest.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := get(rec, s.URL+"/accounts/?b=2&a=1&a=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err = robinhoodtest.NewRecorder(path, robinhoodtest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"a=0&a=1&b=2", "a=1&b=2&a=0", "b=2&a=1&a=0"} {
+		res, err := get(rec, "http://replay.invalid/accounts/?"+q)
+		if err != nil {
+			t.Errorf("replaying query %s: %v", q, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("replaying query %s: status %d", q, res.StatusCode)
+		}
+	}
+	if _, err := get(rec, "http://replay.invalid/accounts/?a=1&b=2"); err == nil {
+		t.Error("replayed a query missing a parameter")
+	}
+}
@@ -0,0 +1,30 @@
+package robinhoodtest
+
+import (
+	"net/http"
+
+	"astuart.co/go-robinhood"
+)
+
+// AddDividend adds a dividend, filling in its id and URL, and defaulting its
+// account to the first account. The stored dividend is returned.
+func (s *Server) AddDividend(d robinhood.Dividend) robinhood.Dividend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d.Account == "" && len(s.accounts) > 0 {
+		d.Account = s.accounts[0].URL
+	}
+	d.ID = s.newID()
+	d.URL = s.URL + "/dividends/" + d.ID + "/"
+	s.dividends = append(s.dividends, &d)
+	return d
+}
+
+func (s *Server) handleDividends(w http.ResponseWriter, r *http.Request) {
+	results := make([]interface{}, len(s.dividends))
+	for i, d := range s.dividends {
+		results[i] = d
+	}
+	s.writePage(w, r, results)
+}
@@ -0,0 +1,1045 @@
+// Package robinhoodtest provides an in-process fake of the Robinhood API for
+// testing code built on astuart.co/go-robinhood without touching a real
+// account.
+//
+// A Server is seeded with users, accounts, instruments, quotes and so on, and
+// a robinhood.Client is pointed at it with robinhood.WithBaseURL (or simply
+// with Server.Client). Orders sent to it rest until filled by a script via
+// Fill, and failures can be injected with Inject.
+package robinhoodtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	robinhood "astuart.co/go-robinhood"
+	"golang.org/x/time/rate"
+)
+
+// DefaultPageSize is the number of results a Server returns per page of a
+// list endpoint unless its PageSize is changed.
+const DefaultPageSize = 10
+
+// A Server is a fake Robinhood API served over HTTP on a loopback address.
+type Server struct {
+	*httptest.Server
+
+	// PageSize is the number of results returned per page of a list
+	// endpoint.
+	PageSize int
+
+	// TokenLifetime, if positive, overrides the lifetime requested by
+	// clients for the access tokens the Server issues.
+	TokenLifetime time.Duration
+
+	// AutoFill, if true, fills each order in full as soon as it is
+	// submitted, at its limit price or else the instrument's last trade
+	// price.
+	AutoFill bool
+
+	mu          sync.Mutex
+	seq         int
+	users       map[string]*user
+	tokens      map[string]*token
+	refreshes   map[string]string
+	challenges  map[string]*challenge
+	accounts    []*robinhood.Account
+	portfolios  []*robinhood.Portfolio
+	positions   []*robinhood.Position
+	instruments []*robinhood.Instrument
+	quotes      map[string]*robinhood.Quote
+	watchlists  []*watchlist
+	orders      []*order
+	faults      []*Fault
+
+	achRelationships []*robinhood.ACHRelationship
+	transfers        []*transfer
+	dividends        []*robinhood.Dividend
+
+	profile           robinhood.User
+	basicInfo         robinhood.BasicInfo
+	investmentProfile robinhood.InvestmentProfile
+	employment        robinhood.Employment
+}
+
+type user struct {
+	password   string
+	mfa        string
+	totpSecret string
+
+	challengeType robinhood.ChallengeType
+	challengeCode string
+	// devices are the device tokens that have passed a challenge.
+	devices map[string]bool
+}
+
+// A challenge is an SMS or email verification issued for a login.
+type challenge struct {
+	robinhood.MFAChallenge
+	username string
+	code     string
+}
+
+// A token is an access token issued to a user, valid until expiry (if set).
+type token struct {
+	username string
+	expiry   time.Time
+}
+
+type watchlist struct {
+	robinhood.Watchlist
+	instruments []string
+}
+
+// watchlistItem is an entry of a watchlist, as returned by the API.
+type watchlistItem struct {
+	Instrument string `json:"instrument"`
+	URL        string `json:"url"`
+}
+
+// order is an order as stored and returned by the Server, which includes the
+// symbol it was sent with.
+type order struct {
+	robinhood.Order
+	Symbol string `json:"symbol"`
+}
+
+// A Fault describes requests the Server should fail instead of serving.
+type Fault struct {
+	// Method and Path select the requests to fail. An empty Method matches
+	// any method, and Path matches any request path it is a prefix of, e.g.
+	// "/orders/".
+	Method string
+	Path   string
+
+	// StatusCode, Header and Body make up the response.
+	StatusCode int
+	Header     http.Header
+	Body       string
+
+	// Times is the number of matching requests to fail. Zero means one.
+	Times int
+}
+
+// NewServer starts and returns a new, empty Server. The caller should call
+// Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		PageSize:   DefaultPageSize,
+		users:      map[string]*user{},
+		tokens:     map[string]*token{},
+		refreshes:  map[string]string{},
+		challenges: map[string]*challenge{},
+		quotes:     map[string]*robinhood.Quote{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth2/token/", s.handleLogin)
+	mux.HandleFunc("POST /oauth2/revoke_token/", s.handleRevoke)
+	mux.HandleFunc("POST /challenge/{id}/respond/{$}", s.handleChallengeRespond)
+	mux.HandleFunc("GET /user/{$}", s.authed(s.handleUser))
+	mux.HandleFunc("GET /user/basic_info/{$}", s.authed(s.handleBasicInfo))
+	mux.HandleFunc("GET /user/investment_profile/{$}", s.authed(s.handleInvestmentProfile))
+	mux.HandleFunc("GET /user/employment/{$}", s.authed(s.handleEmployment))
+	mux.HandleFunc("GET /accounts/{$}", s.authed(s.handleAccounts))
+	mux.HandleFunc("GET /accounts/{number}/{$}", s.authed(s.handleAccount))
+	mux.HandleFunc("GET /accounts/{number}/positions/{$}", s.authed(s.handlePositions))
+	mux.HandleFunc("GET /portfolios/{$}", s.authed(s.handlePortfolios))
+	mux.HandleFunc("GET /portfolios/{number}/{$}", s.authed(s.handlePortfolio))
+	mux.HandleFunc("GET /quotes/{$}", s.authed(s.handleQuotes))
+	mux.HandleFunc("GET /instruments/{$}", s.authed(s.handleInstruments))
+	mux.HandleFunc("GET /instruments/{id}/{$}", s.authed(s.handleInstrument))
+	mux.HandleFunc("GET /watchlists/{$}", s.authed(s.handleWatchlists))
+	mux.HandleFunc("GET /watchlists/{name}/{$}", s.authed(s.handleWatchlist))
+	mux.HandleFunc("GET /orders/{$}", s.authed(s.handleOrders))
+	mux.HandleFunc("POST /orders/{$}", s.authed(s.handleSendOrder))
+	mux.HandleFunc("GET /orders/{id}", s.authed(s.handleOrder))
+	mux.HandleFunc("GET /orders/{id}/{$}", s.authed(s.handleOrder))
+	mux.HandleFunc("POST /orders/{id}/cancel/{$}", s.authed(s.handleCancelOrder))
+	mux.HandleFunc("GET /dividends/{$}", s.authed(s.handleDividends))
+	mux.HandleFunc("GET /ach/relationships/{$}", s.authed(s.handleACHRelationships))
+	mux.HandleFunc("GET /ach/transfers/{$}", s.authed(s.handleTransfers))
+	mux.HandleFunc("POST /ach/transfers/{$}", s.authed(s.handleSendTransfer))
+	mux.HandleFunc("GET /ach/transfers/{id}/{$}", s.authed(s.handleTransfer))
+	mux.HandleFunc("POST /ach/transfers/{id}/cancel/{$}", s.authed(s.handleCancelTransfer))
+
+	s.Server = httptest.NewServer(s.faulty(mux))
+	return s
+}
+
+// Client returns a robinhood.Client authenticated with a fresh token and
+// pointed at the Server, with client-side rate limiting disabled. Further
+// options are applied after those.
+func (s *Server) Client(opts ...robinhood.Option) (*robinhood.Client, error) {
+	tkn := robinhood.Token(s.IssueToken(""))
+	opts = append([]robinhood.Option{
+		robinhood.WithBaseURL(s.URL),
+		robinhood.WithRateLimit(rate.Inf, 0),
+	}, opts...)
+	return robinhood.Dial(&tkn, opts...)
+}
+
+// Creds returns Creds that log in to the Server.
+func (s *Server) Creds(username, password string) *robinhood.Creds {
+	c := robinhood.NewCreds(username, password)
+	c.BaseURL = s.URL
+	return c
+}
+
+// AddUser registers a login. If mfa is not empty, logging in requires it as
+// the MFA code.
+func (s *Server) AddUser(username, password, mfa string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[username] = &user{password: password, mfa: mfa}
+}
+
+// AddTOTPUser registers a login that requires an authenticator app code
+// generated from secret. Codes from the adjacent periods are accepted too.
+func (s *Server) AddTOTPUser(username, password, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[username] = &user{password: password, totpSecret: secret}
+}
+
+// RequireChallenge makes logins by username be blocked by a verification
+// challenge of the given type until code is sent in response to it. Once a
+// login with a device token has passed the challenge, later logins with the
+// same device token are not challenged.
+func (s *Server) RequireChallenge(username string, typ robinhood.ChallengeType, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[username]; ok {
+		u.challengeType = typ
+		u.challengeCode = code
+	}
+}
+
+// IssueToken returns a new access token accepted by the Server, as if
+// username had logged in.
+func (s *Server) IssueToken(username string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken(username, 0)
+}
+
+// ExpireToken makes tkn expire now, as if its lifetime had passed.
+func (s *Server) ExpireToken(tkn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tokens[tkn]; ok {
+		t.expiry = time.Now()
+	}
+}
+
+// RevokeToken makes the Server reject tkn from now on.
+func (s *Server) RevokeToken(tkn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, tkn)
+}
+
+// AddAccount adds an account, filling in its URLs from its AccountNumber
+// (which is generated if empty). An empty portfolio is added alongside it.
+// The stored account is returned.
+func (s *Server) AddAccount(a robinhood.Account) robinhood.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a.AccountNumber == "" {
+		a.AccountNumber = fmt.Sprintf("5RH%05d", s.nextSeq())
+	}
+	a.URL = s.URL + "/accounts/" + a.AccountNumber + "/"
+	a.Positions = a.URL + "positions/"
+	a.Portfolio = s.URL + "/portfolios/" + a.AccountNumber + "/"
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = a.CreatedAt
+	}
+	s.accounts = append(s.accounts, &a)
+	s.portfolios = append(s.portfolios, &robinhood.Portfolio{
+		Account: a.URL,
+		URL:     a.Portfolio,
+	})
+	return a
+}
+
+// SetUser sets the user returned by the user endpoint, filling in its URLs.
+func (s *Server) SetUser(u robinhood.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.URL = s.URL + "/user/"
+	u.BasicInfo = u.URL + "basic_info/"
+	u.InvestmentProfile = u.URL + "investment_profile/"
+	u.Employment = u.URL + "employment/"
+	s.profile = u
+}
+
+// SetBasicInfo sets the user's basic information.
+func (s *Server) SetBasicInfo(b robinhood.BasicInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b.User = s.URL + "/user/"
+	s.basicInfo = b
+}
+
+// SetInvestmentProfile sets the user's investment profile.
+func (s *Server) SetInvestmentProfile(p robinhood.InvestmentProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.User = s.URL + "/user/"
+	s.investmentProfile = p
+}
+
+// SetEmployment sets the user's employment information.
+func (s *Server) SetEmployment(e robinhood.Employment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.User = s.URL + "/user/"
+	s.employment = e
+}
+
+// SetPortfolio replaces the portfolio of the account whose URL is p.Account.
+func (s *Server) SetPortfolio(p robinhood.Portfolio) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, old := range s.portfolios {
+		if old.Account == p.Account {
+			p.URL = old.URL
+			s.portfolios[i] = &p
+			return
+		}
+	}
+	s.portfolios = append(s.portfolios, &p)
+}
+
+// AddInstrument adds an instrument, filling in its ID (if empty), URL and
+// quote URL, and returns the stored instrument.
+func (s *Server) AddInstrument(inst robinhood.Instrument) robinhood.Instrument {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inst.ID == "" {
+		inst.ID = s.newID()
+	}
+	inst.URL = s.URL + "/instruments/" + inst.ID + "/"
+	inst.Quote = s.URL + "/quotes/" + inst.Symbol + "/"
+	if inst.State == "" {
+		inst.State = "active"
+	}
+	s.instruments = append(s.instruments, &inst)
+	return inst
+}
+
+// SetQuote sets the quote returned for q.Symbol.
+func (s *Server) SetQuote(q robinhood.Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotes[q.Symbol] = &q
+}
+
+// SetPosition sets the position held by the account at p.Account in the
+// instrument at p.Instrument.
+func (s *Server) SetPosition(p robinhood.Position) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pos := s.position(p.Account, p.Instrument)
+	url := pos.URL
+	*pos = p
+	pos.URL = url
+}
+
+// AddWatchlist adds a watchlist with the given name holding the instruments
+// with the given URLs.
+func (s *Server) AddWatchlist(name string, instrumentURLs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watchlists = append(s.watchlists, &watchlist{
+		Watchlist: robinhood.Watchlist{
+			Name: name,
+			URL:  s.URL + "/watchlists/" + name + "/",
+		},
+		instruments: instrumentURLs,
+	})
+}
+
+// Inject makes the Server fail requests matching f.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// Orders returns every order the Server has received, oldest first.
+func (s *Server) Orders() []robinhood.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]robinhood.Order, len(s.orders))
+	for i, o := range s.orders {
+		orders[i] = o.Order
+	}
+	return orders
+}
+
+// Fill executes quantity shares of the order with the given id at price,
+// updating the order's state and the account's position.
+func (s *Server) Fill(id string, quantity, price robinhood.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.order(id)
+	if o == nil {
+		return fmt.Errorf("robinhoodtest: no order %q", id)
+	}
+	return s.fill(o, quantity, price)
+}
+
+func (s *Server) fill(o *order, quantity, price robinhood.Decimal) error {
+	switch o.State {
+	case robinhood.OrderState_Filled, robinhood.OrderState_Canceled,
+		robinhood.OrderState_Rejected, robinhood.OrderState_Failed:
+		return fmt.Errorf("robinhoodtest: order %s is %s", o.Id, o.State)
+	}
+	if o.CumulativeQuantity.Add(quantity).Cmp(o.Quantity) > 0 {
+		return fmt.Errorf("robinhoodtest: filling %v would exceed order quantity %v", quantity, o.Quantity)
+	}
+
+	now := time.Now()
+	o.Executions = append(o.Executions, robinhood.Execution{
+		Id:             s.newID(),
+		Price:          price,
+		Quantity:       quantity,
+		Timestamp:      now,
+		SettlementDate: now.AddDate(0, 0, 2).Format("2006-01-02"),
+	})
+	o.AveragePrice = averagePrice(o.AveragePrice, o.CumulativeQuantity, price, quantity)
+	o.CumulativeQuantity = o.CumulativeQuantity.Add(quantity)
+	o.State = robinhood.OrderState_PartiallyFilled
+	if o.CumulativeQuantity.Equal(o.Quantity) {
+		o.State = robinhood.OrderState_Filled
+	}
+	o.LastTransactionAt = now.Format(time.RFC3339)
+	o.UpdatedAt = now
+
+	pos := s.position(o.Account, o.Instrument)
+	if o.Side == robinhood.Side_Buy {
+		pos.AverageBuyPrice = averagePrice(pos.AverageBuyPrice, pos.Quantity, price, quantity)
+		pos.Quantity = pos.Quantity.Add(quantity)
+	} else {
+		pos.Quantity = pos.Quantity.Sub(quantity)
+	}
+	pos.UpdatedAt = now
+	return nil
+}
+
+// averagePrice returns the average price of q1 shares at p1 and q2 shares at
+// p2.
+func averagePrice(p1, q1, p2, q2 robinhood.Decimal) robinhood.Decimal {
+	total := q1.Add(q2)
+	if total.IsZero() {
+		return robinhood.Decimal{}
+	}
+	return p1.Mul(q1).Add(p2.Mul(q2)).Div(total, 4)
+}
+
+// nextSeq returns a number unique within the Server.
+func (s *Server) nextSeq() int {
+	s.seq++
+	return s.seq
+}
+
+// newID returns a new UUID-shaped identifier.
+func (s *Server) newID() string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012x", s.nextSeq())
+}
+
+// issueToken issues an access token to username that is valid for lifetime,
+// or forever if lifetime is 0.
+func (s *Server) issueToken(username string, lifetime time.Duration) string {
+	tkn := fmt.Sprintf("test-token-%d", s.nextSeq())
+	t := &token{username: username}
+	if lifetime > 0 {
+		t.expiry = time.Now().Add(lifetime)
+	}
+	s.tokens[tkn] = t
+	return tkn
+}
+
+// writeToken issues an access and refresh token to username and writes them
+// as a login response.
+func (s *Server) writeToken(w http.ResponseWriter, username string, expiresIn int) {
+	lifetime := time.Duration(expiresIn) * time.Second
+	if s.TokenLifetime > 0 {
+		lifetime = s.TokenLifetime
+	}
+	refresh := fmt.Sprintf("test-refresh-%d", s.nextSeq())
+	s.refreshes[refresh] = username
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  s.issueToken(username, lifetime),
+		"refresh_token": refresh,
+		"token_type":    "Bearer",
+		"expires_in":    int(lifetime / time.Second),
+		"scope":         "internal",
+	})
+}
+
+// position returns the position of account in instrument, creating it if
+// needed.
+func (s *Server) position(account, instrument string) *robinhood.Position {
+	for _, p := range s.positions {
+		if p.Account == account && p.Instrument == instrument {
+			return p
+		}
+	}
+	now := time.Now()
+	p := &robinhood.Position{
+		Meta: robinhood.Meta{
+			CreatedAt: now,
+			UpdatedAt: now,
+			URL:       account + "positions/" + lastSegment(instrument) + "/",
+		},
+		Account:    account,
+		Instrument: instrument,
+	}
+	s.positions = append(s.positions, p)
+	return p
+}
+
+func (s *Server) order(id string) *order {
+	for _, o := range s.orders {
+		if o.Id == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *Server) instrument(url string) *robinhood.Instrument {
+	for _, inst := range s.instruments {
+		if inst.URL == url {
+			return inst
+		}
+	}
+	return nil
+}
+
+// faulty serves any injected Fault matching a request in place of next.
+func (s *Server) faulty(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		var f *Fault
+		for i, candidate := range s.faults {
+			if (candidate.Method == "" || candidate.Method == r.Method) && strings.HasPrefix(r.URL.Path, candidate.Path) {
+				f = candidate
+				f.Times--
+				if f.Times == 0 {
+					s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				}
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		if f == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for k, vs := range f.Header {
+			w.Header()[k] = vs
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(f.StatusCode)
+		fmt.Fprint(w, f.Body)
+	})
+}
+
+// authed rejects requests to h that lack a token issued by the Server. The
+// Server's lock is held while h runs.
+func (s *Server) authed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		tkn := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if t, ok := s.tokens[tkn]; !ok || (!t.expiry.IsZero() && time.Now().After(t.expiry)) {
+			writeDetail(w, http.StatusUnauthorized, "Invalid token.")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username     string `json:"username"`
+		Password     string `json:"password"`
+		MFA          string `json:"mfa_code"`
+		DeviceToken  string `json:"device_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		GrantType    string `json:"grant_type"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDetail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.GrantType {
+	case "password":
+	case "refresh_token":
+		username, ok := s.refreshes[req.RefreshToken]
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		// Refresh tokens are single use.
+		delete(s.refreshes, req.RefreshToken)
+		s.writeToken(w, username, req.ExpiresIn)
+		return
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	u, ok := s.users[req.Username]
+	if !ok || u.password != req.Password {
+		writeDetail(w, http.StatusBadRequest, "Unable to log in with provided credentials.")
+		return
+	}
+	if u.challengeCode != "" && (req.DeviceToken == "" || !u.devices[req.DeviceToken]) {
+		id := r.Header.Get("X-Robinhood-Challenge-Response-Id")
+		ch, ok := s.challenges[id]
+		if !ok || ch.username != req.Username || ch.Status != "validated" {
+			s.writeChallenge(w, req.Username, u)
+			return
+		}
+		delete(s.challenges, id)
+		if req.DeviceToken != "" {
+			if u.devices == nil {
+				u.devices = map[string]bool{}
+			}
+			u.devices[req.DeviceToken] = true
+		}
+	}
+	if (u.mfa != "" || u.totpSecret != "") && req.MFA == "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"mfa_required": true, "mfa_type": "app"})
+		return
+	}
+	if (u.mfa != "" && req.MFA != u.mfa) || (u.totpSecret != "" && !validTOTP(u.totpSecret, req.MFA)) {
+		writeDetail(w, http.StatusBadRequest, "Please enter a valid code.")
+		return
+	}
+
+	s.writeToken(w, req.Username, req.ExpiresIn)
+}
+
+// validTOTP returns whether code is the TOTP code for secret in the current
+// period or either adjacent one.
+func validTOTP(secret, code string) bool {
+	now := time.Now()
+	for _, d := range []time.Duration{-30 * time.Second, 0, 30 * time.Second} {
+		if want, err := robinhood.TOTP(secret, now.Add(d)); err == nil && want == code {
+			return true
+		}
+	}
+	return false
+}
+
+// handleRevoke revokes an access or refresh token.
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDetail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, req.Token)
+	delete(s.refreshes, req.Token)
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeChallenge issues a new challenge for a login by username and writes
+// it as a blocked login response.
+func (s *Server) writeChallenge(w http.ResponseWriter, username string, u *user) {
+	ch := &challenge{
+		MFAChallenge: robinhood.MFAChallenge{
+			ID:                s.newID(),
+			Type:              u.challengeType,
+			Status:            "issued",
+			RemainingAttempts: 3,
+			ExpiresAt:         time.Now().Add(5 * time.Minute),
+		},
+		username: username,
+		code:     u.challengeCode,
+	}
+	s.challenges[ch.ID] = ch
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"detail":    "Request blocked, challenge issued.",
+		"challenge": ch.MFAChallenge,
+	})
+}
+
+func (s *Server) handleChallengeRespond(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Response string `json:"response"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDetail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.challenges[r.PathValue("id")]
+	if !ok || ch.Status == "failed" {
+		writeDetail(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	if req.Response != ch.code {
+		ch.RemainingAttempts--
+		if ch.RemainingAttempts <= 0 {
+			ch.Status = "failed"
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"detail":    "Challenge response is invalid.",
+			"challenge": ch.MFAChallenge,
+		})
+		return
+	}
+	ch.Status = "validated"
+	writeJSON(w, http.StatusOK, ch.MFAChallenge)
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.profile)
+}
+
+func (s *Server) handleBasicInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.basicInfo)
+}
+
+func (s *Server) handleInvestmentProfile(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.investmentProfile)
+}
+
+func (s *Server) handleEmployment(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.employment)
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	results := make([]interface{}, len(s.accounts))
+	for i, a := range s.accounts {
+		results[i] = a
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	for _, a := range s.accounts {
+		if a.AccountNumber == r.PathValue("number") {
+			writeJSON(w, http.StatusOK, a)
+			return
+		}
+	}
+	writeDetail(w, http.StatusNotFound, "Not found.")
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	account := s.URL + "/accounts/" + r.PathValue("number") + "/"
+	var results []interface{}
+	for _, p := range s.positions {
+		if p.Account == account {
+			results = append(results, p)
+		}
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handlePortfolios(w http.ResponseWriter, r *http.Request) {
+	results := make([]interface{}, len(s.portfolios))
+	for i, p := range s.portfolios {
+		results[i] = p
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	url := s.URL + "/portfolios/" + r.PathValue("number") + "/"
+	for _, p := range s.portfolios {
+		if p.URL == url {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeDetail(w, http.StatusNotFound, "Not found.")
+}
+
+func (s *Server) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	var results []interface{}
+	for _, sym := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if q, ok := s.quotes[strings.ToUpper(sym)]; ok {
+			results = append(results, q)
+		} else {
+			// The API returns null for unknown symbols.
+			results = append(results, nil)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+func (s *Server) handleInstruments(w http.ResponseWriter, r *http.Request) {
+	sym := r.URL.Query().Get("symbol")
+	var results []interface{}
+	for _, inst := range s.instruments {
+		if sym == "" || strings.EqualFold(inst.Symbol, sym) {
+			results = append(results, inst)
+		}
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleInstrument(w http.ResponseWriter, r *http.Request) {
+	for _, inst := range s.instruments {
+		if inst.ID == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, inst)
+			return
+		}
+	}
+	writeDetail(w, http.StatusNotFound, "Not found.")
+}
+
+func (s *Server) handleWatchlists(w http.ResponseWriter, r *http.Request) {
+	results := make([]interface{}, len(s.watchlists))
+	for i, wl := range s.watchlists {
+		results[i] = wl
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	for _, wl := range s.watchlists {
+		if wl.Name != r.PathValue("name") {
+			continue
+		}
+		results := make([]interface{}, len(wl.instruments))
+		for i, inst := range wl.instruments {
+			results[i] = watchlistItem{
+				Instrument: inst,
+				URL:        wl.URL + lastSegment(inst) + "/",
+			}
+		}
+		s.writePage(w, r, results)
+		return
+	}
+	writeDetail(w, http.StatusNotFound, "Not found.")
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	inst := r.URL.Query().Get("instrument")
+	var since time.Time
+	if v := r.URL.Query().Get("updated_at[gte]"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string][]string{"updated_at": {"Enter a valid date/time."}})
+			return
+		}
+	}
+	var results []interface{}
+	// The API lists the most recent orders first.
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		o := s.orders[i]
+		if (inst == "" || o.Instrument == inst) && !o.UpdatedAt.Before(since) {
+			results = append(results, o)
+		}
+	}
+	s.writePage(w, r, results)
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	o := s.order(r.PathValue("id"))
+	if o == nil {
+		writeDetail(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (s *Server) handleSendOrder(w http.ResponseWriter, r *http.Request) {
+	var req robinhood.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDetail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fieldErrs := map[string][]string{}
+	inst := s.instrument(req.Instrument)
+	if inst == nil {
+		fieldErrs["instrument"] = []string{"Invalid hyperlink - Object does not exist."}
+	}
+	if !s.hasAccount(req.Account) {
+		fieldErrs["account"] = []string{"Invalid hyperlink - Object does not exist."}
+	}
+	if req.Quantity <= 0 {
+		fieldErrs["quantity"] = []string{"Ensure this value is greater than 0."}
+	}
+	if req.Type == robinhood.OrderType_Limit && req.Price.Sign() <= 0 {
+		fieldErrs["price"] = []string{"A valid number is required."}
+	}
+	if len(fieldErrs) > 0 {
+		writeJSON(w, http.StatusBadRequest, fieldErrs)
+		return
+	}
+
+	now := time.Now()
+	id := s.newID()
+	o := &order{
+		Order: robinhood.Order{
+			Meta: robinhood.Meta{
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			Id:                     id,
+			URL:                    s.URL + "/orders/" + id + "/",
+			Cancel:                 s.URL + "/orders/" + id + "/cancel/",
+			State:                  robinhood.OrderState_Confirmed,
+			ClientId:               req.RefID,
+			ExtendedHours:          req.ExtendedHours,
+			OverrideDayTradeChecks: req.OverrideDayTradeChecks,
+			OverrideDtbpChecks:     req.OverrideDtbpChecks,
+			Account:                req.Account,
+			Instrument:             req.Instrument,
+			Type:                   req.Type,
+			TimeInForce:            req.TimeInForce,
+			Trigger:                req.Trigger,
+			Side:                   req.Side,
+			Price:                  req.Price,
+			StopPrice:              req.StopPrice,
+			Quantity:               robinhood.DecimalFromInt(int64(req.Quantity)),
+		},
+		Symbol: req.Symbol,
+	}
+	o.Position = o.Account + "positions/" + inst.ID + "/"
+	s.orders = append(s.orders, o)
+
+	if s.AutoFill {
+		price := o.Price
+		if q, ok := s.quotes[inst.Symbol]; ok && o.Type == robinhood.OrderType_Market {
+			price = q.LastTradePrice
+		}
+		s.fill(o, o.Quantity, price)
+	}
+
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	o := s.order(r.PathValue("id"))
+	if o == nil {
+		writeDetail(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	switch o.State {
+	case robinhood.OrderState_Filled, robinhood.OrderState_Canceled,
+		robinhood.OrderState_Rejected, robinhood.OrderState_Failed:
+		writeDetail(w, http.StatusBadRequest, "Order cannot be cancelled.")
+		return
+	}
+	o.State = robinhood.OrderState_Canceled
+	o.Cancel = ""
+	o.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (s *Server) hasAccount(url string) bool {
+	for _, a := range s.accounts {
+		if a.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+// writePage writes the page of results selected by the request's cursor
+// parameter, with next and previous links to its neighbours.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, results []interface{}) {
+	size := s.PageSize
+	if size <= 0 {
+		size = len(results) + 1
+	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	if start < 0 || start > len(results) {
+		start = len(results)
+	}
+	end := start + size
+	if end > len(results) {
+		end = len(results)
+	}
+
+	page := map[string]interface{}{
+		"previous": nil,
+		"next":     nil,
+		"results":  results[start:end],
+	}
+	if start > 0 {
+		prev := start - size
+		if prev < 0 {
+			prev = 0
+		}
+		page["previous"] = s.cursorURL(r, prev)
+	}
+	if end < len(results) {
+		page["next"] = s.cursorURL(r, end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// cursorURL returns the URL of r with its cursor parameter set to cursor.
+func (s *Server) cursorURL(r *http.Request, cursor int) string {
+	q := r.URL.Query()
+	q.Set("cursor", strconv.Itoa(cursor))
+	return s.URL + r.URL.Path + "?" + q.Encode()
+}
+
+// lastSegment returns the last non-empty path segment of url.
+func lastSegment(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	return url[strings.LastIndex(url, "/")+1:]
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeDetail(w http.ResponseWriter, code int, detail string) {
+	writeJSON(w, code, map[string]string{"detail": detail})
+}
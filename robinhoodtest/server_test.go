@@ -0,0 +1,736 @@
+package robinhoodtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+	"astuart.co/go-robinhood/robinhoodtest"
+	"golang.org/x/time/rate"
+)
+
+// fastRetry retries quickly enough for tests.
+var fastRetry = robinhood.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func newServer(t *testing.T) *robinhoodtest.Server {
+	t.Helper()
+	s := robinhoodtest.NewServer()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func dial(t *testing.T, s *robinhoodtest.Server, tg robinhood.TokenGetter, opts ...robinhood.Option) *robinhood.Client {
+	t.Helper()
+	opts = append([]robinhood.Option{
+		robinhood.WithBaseURL(s.URL),
+		robinhood.WithRateLimit(rate.Inf, 0),
+	}, opts...)
+	c, err := robinhood.Dial(tg, opts...)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	return c
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	if c.Token == "" {
+		t.Fatal("no token after login")
+	}
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+
+	_, err := robinhood.Dial(s.Creds("bob", "wrong"))
+	var apiErr *robinhood.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Dial with wrong password: got %v, want 400 APIError", err)
+	}
+}
+
+func TestRefreshBeforeExpiry(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+	// Within the Client's refresh leeway, so every request renews first.
+	s.TokenLifetime = 30 * time.Second
+
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	first := c.AccessToken()
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if c.AccessToken() == first {
+		t.Error("token was not refreshed before it expired")
+	}
+}
+
+func TestReplayAfterRevoke(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	revoked := c.AccessToken()
+	s.RevokeToken(revoked)
+
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts after revoke: %v", err)
+	}
+	if c.AccessToken() == revoked {
+		t.Error("revoked token still in use")
+	}
+}
+
+func TestUnauthorizedWithoutRenewal(t *testing.T) {
+	s := newServer(t)
+	s.AddAccount(robinhood.Account{})
+
+	tkn := robinhood.Token(s.IssueToken(""))
+	c := dial(t, s, &tkn)
+	s.RevokeToken(string(tkn))
+
+	_, err := c.GetAccounts()
+	if !robinhood.IsUnauthorized(err) {
+		t.Fatalf("got %v, want unauthorized", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := newServer(t)
+	s.PageSize = 2
+	for i := 0; i < 5; i++ {
+		s.AddAccount(robinhood.Account{})
+	}
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accts, err := c.GetAccounts()
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if len(accts) != 5 {
+		t.Fatalf("got %d accounts, want 5", len(accts))
+	}
+
+	p := robinhood.NewPager[robinhood.Account](c, s.URL+"/accounts/")
+	var sizes []int
+	for p.HasNext() {
+		page, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		sizes = append(sizes, len(page))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[2] != 1 {
+		t.Errorf("page sizes = %v, want [2 2 1]", sizes)
+	}
+	if _, err := p.Next(context.Background()); err != robinhood.ErrNoMorePages {
+		t.Errorf("Next after last page: got %v, want ErrNoMorePages", err)
+	}
+
+	p = robinhood.NewPager[robinhood.Account](c, s.URL+"/accounts/")
+	p.MaxItems = 3
+	all, err := p.All(context.Background())
+	if err != nil || len(all) != 3 {
+		t.Errorf("All with MaxItems 3: got %d, %v", len(all), err)
+	}
+}
+
+func TestFaultRetried(t *testing.T) {
+	s := newServer(t)
+	s.AddAccount(robinhood.Account{})
+	s.Inject(robinhoodtest.Fault{Method: http.MethodGet, Path: "/accounts/", StatusCode: http.StatusServiceUnavailable, Times: 2})
+
+	c, err := s.Client(robinhood.WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetAccounts(); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+}
+
+func TestFaultNotRetried(t *testing.T) {
+	s := newServer(t)
+	s.AddAccount(robinhood.Account{})
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Inject(robinhoodtest.Fault{Path: "/accounts/", StatusCode: http.StatusServiceUnavailable})
+	_, err = c.GetAccounts()
+	var apiErr *robinhood.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("without a retry policy: got %v, want 503 APIError", err)
+	}
+
+	// Client errors are never retried.
+	c, err = s.Client(robinhood.WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Inject(robinhoodtest.Fault{Path: "/accounts/", StatusCode: http.StatusBadRequest, Times: 3})
+	_, err = c.GetAccounts()
+	var retryErr *robinhood.RetryError
+	if err == nil || errors.As(err, &retryErr) {
+		t.Fatalf("400: got %v, want a single failed attempt", err)
+	}
+}
+
+func TestFaultExhaustsRetries(t *testing.T) {
+	s := newServer(t)
+	s.AddAccount(robinhood.Account{})
+	s.Inject(robinhoodtest.Fault{Path: "/accounts/", StatusCode: http.StatusTooManyRequests, Times: 10})
+
+	c, err := s.Client(robinhood.WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetAccounts()
+	var retryErr *robinhood.RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != fastRetry.MaxAttempts {
+		t.Fatalf("got %v, want RetryError after %d attempts", err, fastRetry.MaxAttempts)
+	}
+	if !robinhood.IsRateLimited(err) {
+		t.Errorf("IsRateLimited(%v) = false", err)
+	}
+}
+
+func TestPostRetriedOnlyWithIdempotencyKey(t *testing.T) {
+	s := newServer(t)
+	a := s.AddAccount(robinhood.Account{})
+	inst := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	c, err := s.Client(robinhood.WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := orderRequest(a, inst)
+	s.Inject(robinhoodtest.Fault{Method: http.MethodPost, Path: "/orders/", StatusCode: http.StatusBadGateway})
+	if _, err := c.SendOrder(req); err == nil {
+		t.Fatal("SendOrder without RefID was retried")
+	}
+
+	req.RefID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	s.Inject(robinhoodtest.Fault{Method: http.MethodPost, Path: "/orders/", StatusCode: http.StatusBadGateway})
+	if _, err := c.SendOrder(req); err != nil {
+		t.Fatalf("SendOrder with RefID: %v", err)
+	}
+	if n := len(s.Orders()); n != 1 {
+		t.Errorf("server has %d orders, want 1", n)
+	}
+}
+
+func orderRequest(a robinhood.Account, inst robinhood.Instrument) *robinhood.OrderRequest {
+	return &robinhood.OrderRequest{
+		Account:     a.URL,
+		Instrument:  inst.URL,
+		Symbol:      inst.Symbol,
+		Type:        robinhood.OrderType_Limit,
+		TimeInForce: robinhood.TimeInForce_GoodForDay,
+		Trigger:     robinhood.Trigger_Imediate,
+		Price:       robinhood.MustParseDecimal("150.25"),
+		Quantity:    10,
+		Side:        robinhood.Side_Buy,
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	s := newServer(t)
+	a := s.AddAccount(robinhood.Account{})
+	inst := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := c.SendOrder(orderRequest(a, inst))
+	if err != nil {
+		t.Fatalf("SendOrder: %v", err)
+	}
+	if o.State != robinhood.OrderState_Confirmed {
+		t.Errorf("new order state = %s, want confirmed", o.State)
+	}
+
+	if err := s.Fill(o.Id, robinhood.DecimalFromInt(4), robinhood.MustParseDecimal("150.00")); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	o, err = c.GetOrder(o.Id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if o.State != robinhood.OrderState_PartiallyFilled || !o.CumulativeQuantity.Equal(robinhood.DecimalFromInt(4)) {
+		t.Errorf("after partial fill: state %s, cumulative %s", o.State, o.CumulativeQuantity)
+	}
+
+	positions, err := c.GetPositions(a)
+	if err != nil || len(positions) != 1 || !positions[0].Quantity.Equal(robinhood.DecimalFromInt(4)) {
+		t.Errorf("positions after fill: %+v, %v", positions, err)
+	}
+
+	if err := c.CancelOrder(o.Id); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	o, err = c.GetOrder(o.Id)
+	if err != nil || o.State != robinhood.OrderState_Canceled {
+		t.Errorf("after cancel: state %s, %v", o.State, err)
+	}
+	if err := c.CancelOrder(o.Id); err == nil {
+		t.Error("cancelling a cancelled order succeeded")
+	}
+	if err := s.Fill(o.Id, robinhood.DecimalFromInt(1), robinhood.MustParseDecimal("150.00")); err == nil {
+		t.Error("filling a cancelled order succeeded")
+	}
+}
+
+func TestOrderFilled(t *testing.T) {
+	s := newServer(t)
+	a := s.AddAccount(robinhood.Account{})
+	inst := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := c.SendOrder(orderRequest(a, inst))
+	if err != nil {
+		t.Fatalf("SendOrder: %v", err)
+	}
+	s.Fill(o.Id, robinhood.DecimalFromInt(4), robinhood.MustParseDecimal("150"))
+	s.Fill(o.Id, robinhood.DecimalFromInt(6), robinhood.MustParseDecimal("151"))
+
+	o, err = c.GetOrder(o.Id)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if o.State != robinhood.OrderState_Filled || len(o.Executions) != 2 {
+		t.Errorf("state %s with %d executions, want filled with 2", o.State, len(o.Executions))
+	}
+	if want := robinhood.MustParseDecimal("150.6"); !o.AveragePrice.Equal(want) {
+		t.Errorf("average price %s, want %s", o.AveragePrice, want)
+	}
+	if err := c.CancelOrder(o.Id); err == nil {
+		t.Error("cancelling a filled order succeeded")
+	}
+}
+
+func TestSendOrderValidation(t *testing.T) {
+	s := newServer(t)
+	s.AddAccount(robinhood.Account{})
+	c, err := s.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.SendOrder(&robinhood.OrderRequest{Account: "nope", Instrument: "nope", Side: robinhood.Side_Buy})
+	var apiErr *robinhood.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want APIError", err)
+	}
+	for _, field := range []string{"account", "instrument", "quantity"} {
+		if len(apiErr.FieldErrors[field]) == 0 {
+			t.Errorf("no error for field %s in %v", field, apiErr)
+		}
+	}
+}
+
+// concurrencyTransport records the most requests to paths with prefix that
+// were in flight at once.
+type concurrencyTransport struct {
+	prefix string
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (t *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, t.prefix) {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	t.mu.Lock()
+	t.inFlight++
+	t.max = max(t.max, t.inFlight)
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.inFlight--
+		t.mu.Unlock()
+	}()
+	time.Sleep(5 * time.Millisecond)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWatchlistInstruments(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	var urls []string
+	for i := 0; i < 12; i++ {
+		urls = append(urls, s.AddInstrument(robinhood.Instrument{Symbol: fmt.Sprintf("SYM%d", i)}).URL)
+	}
+	s.AddWatchlist("Default", urls...)
+
+	rt := &concurrencyTransport{prefix: "/instruments/"}
+	c := dial(t, s, s.Creds("bob", "hunter2"), robinhood.WithTransport(rt))
+	wls, err := c.GetWatchlists()
+	if err != nil || len(wls) != 1 {
+		t.Fatalf("GetWatchlists: got %v, %v, want 1 watchlist", wls, err)
+	}
+
+	insts, err := wls[0].GetInstruments()
+	if err != nil {
+		t.Fatalf("GetInstruments: %v", err)
+	}
+	if len(insts) != len(urls) {
+		t.Fatalf("got %d instruments, want %d", len(insts), len(urls))
+	}
+	for i, inst := range insts {
+		if inst.URL != urls[i] {
+			t.Errorf("instrument %d: got %s, want %s", i, inst.URL, urls[i])
+		}
+	}
+	if rt.max > 4 || rt.max < 2 {
+		t.Errorf("%d lookups in flight at once, want up to 4", rt.max)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := wls[0].GetInstrumentsContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetInstruments canceled: got %v, want context.Canceled", err)
+	}
+}
+
+func TestACHTransfers(t *testing.T) {
+	d := robinhood.MustParseDecimal
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	acct := s.AddAccount(robinhood.Account{Cash: d("100"), CashAvailableForWithdrawal: d("100")})
+	rel := s.AddACHRelationship(robinhood.ACHRelationship{})
+
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	ac := c.ForAccount(acct)
+
+	rels, err := c.GetACHRelationships()
+	if err != nil || len(rels) != 1 || rels[0].URL != rel.URL {
+		t.Fatalf("GetACHRelationships: got %v, %v, want %s", rels, err, rel.URL)
+	}
+
+	dep, err := ac.Deposit(rel, d("50"))
+	if err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+	if dep.State != robinhood.TransferState_Pending || dep.Direction != robinhood.TransferDirection_Deposit || !dep.Amount.Equal(d("50")) {
+		t.Errorf("Deposit: got %s %s of %s, want a pending deposit of 50", dep.State, dep.Direction, dep.Amount)
+	}
+
+	// The pending deposit isn't available to withdraw yet.
+	if _, err := ac.Withdraw(rel, d("120")); err == nil || !strings.Contains(err.Error(), "only 100 is available") {
+		t.Errorf("Withdraw beyond available cash: got %v", err)
+	}
+	if n := len(s.Transfers()); n != 1 {
+		t.Errorf("%d transfers sent, want the rejected withdrawal not sent", n)
+	}
+	if _, err := c.SendTransfer(&robinhood.TransferRequest{ACHRelationship: rel.URL, Direction: robinhood.TransferDirection_Deposit}); err == nil {
+		t.Error("SendTransfer of nothing succeeded")
+	}
+
+	if err := s.SetTransferState(dep.ID, robinhood.TransferState_Completed); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := ac.Withdraw(rel, d("120"))
+	if err != nil {
+		t.Fatalf("Withdraw after the deposit cleared: %v", err)
+	}
+
+	if err := c.CancelTransfer(wd.ID); err != nil {
+		t.Fatalf("CancelTransfer: %v", err)
+	}
+	if got, err := c.GetTransfer(wd.ID); err != nil || got.State != robinhood.TransferState_Cancelled {
+		t.Errorf("GetTransfer after cancel: got %s, %v, want cancelled", got.State, err)
+	}
+	var apiErr *robinhood.APIError
+	if err := c.CancelTransfer(wd.ID); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("cancelling twice: got %v, want 400 APIError", err)
+	}
+	if err := c.CancelTransfer(dep.ID); err == nil {
+		t.Error("cancelling a completed transfer succeeded")
+	}
+
+	transfers, err := c.GetTransfers()
+	if err != nil || len(transfers) != 2 {
+		t.Fatalf("GetTransfers: got %v, %v, want 2", transfers, err)
+	}
+	if transfers[0].ID != wd.ID || transfers[1].ID != dep.ID {
+		t.Errorf("GetTransfers: got %s, %s, want the most recent first", transfers[0].ID, transfers[1].ID)
+	}
+}
+
+func TestWaitForTransfer(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	acct := s.AddAccount(robinhood.Account{})
+	rel := s.AddACHRelationship(robinhood.ACHRelationship{})
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	ac := c.ForAccount(acct)
+
+	dep, err := ac.Deposit(rel, robinhood.MustParseDecimal("10"))
+	if err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.SetTransferState(dep.ID, robinhood.TransferState_Approved)
+		time.Sleep(20 * time.Millisecond)
+		s.SetTransferState(dep.ID, robinhood.TransferState_Completed)
+	}()
+	got, err := c.WaitForTransfer(context.Background(), dep.ID, 5*time.Millisecond)
+	if err != nil || got.State != robinhood.TransferState_Completed {
+		t.Errorf("WaitForTransfer: got %s, %v, want completed", got.State, err)
+	}
+
+	dep, err = ac.Deposit(rel, robinhood.MustParseDecimal("10"))
+	if err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForTransfer(ctx, dep.ID, 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForTransfer past the deadline: got %v, want context.DeadlineExceeded", err)
+	}
+
+	if _, err := c.WaitForTransfer(context.Background(), "missing", time.Millisecond); !robinhood.IsNotFound(err) {
+		t.Errorf("WaitForTransfer of a missing transfer: got %v, want not found", err)
+	}
+}
+
+func TestDividends(t *testing.T) {
+	d := robinhood.MustParseDecimal
+	s := newServer(t)
+	s.PageSize = 2
+	s.AddUser("bob", "hunter2", "")
+	s.AddAccount(robinhood.Account{})
+	msft := s.AddInstrument(robinhood.Instrument{Symbol: "MSFT"})
+	aapl := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	ko := s.AddInstrument(robinhood.Instrument{Symbol: "KO"})
+
+	add := func(inst robinhood.Instrument, payable string, state robinhood.DividendState, amount string) robinhood.Dividend {
+		return s.AddDividend(robinhood.Dividend{Instrument: inst.URL, PayableDate: payable, State: state, Amount: d(amount)})
+	}
+	divs := []robinhood.Dividend{
+		add(msft, "2023-12-14", robinhood.DividendState_Paid, "1.00"),
+		add(msft, "2024-01-01", robinhood.DividendState_Reinvested, "2.00"),
+		add(aapl, "2024-06-30", robinhood.DividendState_Paid, "0.50"),
+		add(aapl, "2024-07-01", robinhood.DividendState_Voided, "9.99"),
+		add(msft, "2025-03-13", robinhood.DividendState_Pending, "4.00"),
+		add(ko, "2025-04-01", robinhood.DividendState_Voided, "3.00"),
+	}
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+
+	ids := func(divs []robinhood.Dividend) []string {
+		var ids []string
+		for _, d := range divs {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+	date := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	filters := []struct {
+		name string
+		f    *robinhood.DividendFilter
+		want []robinhood.Dividend
+	}{
+		{"all", nil, divs},
+		{"zero", &robinhood.DividendFilter{}, divs},
+		{"state", &robinhood.DividendFilter{State: robinhood.DividendState_Paid}, []robinhood.Dividend{divs[0], divs[2]}},
+		{"instrument", &robinhood.DividendFilter{Instrument: aapl.URL}, []robinhood.Dividend{divs[2], divs[3]}},
+		{"since inclusive", &robinhood.DividendFilter{Since: date("2024-01-01")}, divs[1:]},
+		{"until exclusive", &robinhood.DividendFilter{Until: date("2024-07-01")}, divs[:3]},
+		{"year", &robinhood.DividendFilter{Since: date("2024-01-01"), Until: date("2025-01-01")}, divs[1:4]},
+		{"combined", &robinhood.DividendFilter{Instrument: msft.URL, Since: date("2024-01-01"), State: robinhood.DividendState_Pending}, divs[4:5]},
+	}
+	for _, tt := range filters {
+		got, err := c.GetDividends(tt.f)
+		if err != nil {
+			t.Fatalf("%s: GetDividends: %v", tt.name, err)
+		}
+		if g, w := ids(got), ids(tt.want); strings.Join(g, ",") != strings.Join(w, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, g, w)
+		}
+	}
+
+	totals, err := c.DividendsByInstrument(divs)
+	if err != nil {
+		t.Fatalf("DividendsByInstrument: %v", err)
+	}
+	want := []struct{ symbol, paid, pending string }{
+		{"AAPL", "0.50", "0"},
+		{"KO", "0", "0"},
+		{"MSFT", "3.00", "4.00"},
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("DividendsByInstrument: got %d totals, want %d", len(totals), len(want))
+	}
+	for i, w := range want {
+		got := totals[i]
+		if got.Instrument == nil || got.Instrument.Symbol != w.symbol || !got.Paid.Equal(d(w.paid)) || !got.Pending.Equal(d(w.pending)) {
+			t.Errorf("total %d: got %v paid %s pending %s, want %s paid %s pending %s", i, got.Instrument, got.Paid, got.Pending, w.symbol, w.paid, w.pending)
+		}
+	}
+
+	s.AddDividend(robinhood.Dividend{Instrument: s.URL + "/instruments/missing/", PayableDate: "2025-01-01"})
+	all, err := c.GetDividends(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DividendsByInstrument(all); !robinhood.IsNotFound(err) {
+		t.Errorf("DividendsByInstrument with an unknown instrument: got %v, want not found", err)
+	}
+}
+
+func TestDefaultAccount(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	closed := s.AddAccount(robinhood.Account{Deactivated: true})
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+
+	if _, err := c.DefaultAccount(); !errors.Is(err, robinhood.ErrNoAccount) {
+		t.Errorf("DefaultAccount with only a deactivated account: got %v, want ErrNoAccount", err)
+	}
+	if _, err := c.DefaultAccountClient(); !errors.Is(err, robinhood.ErrNoAccount) {
+		t.Errorf("DefaultAccountClient with only a deactivated account: got %v, want ErrNoAccount", err)
+	}
+
+	open := s.AddAccount(robinhood.Account{})
+	s.AddAccount(robinhood.Account{})
+	a, err := c.DefaultAccount()
+	if err != nil || a.AccountNumber != open.AccountNumber {
+		t.Errorf("DefaultAccount: got %s, %v, want %s", a.AccountNumber, err, open.AccountNumber)
+	}
+	ac, err := c.DefaultAccountClient()
+	if err != nil || ac.Account.URL != open.URL || ac.Client != c {
+		t.Errorf("DefaultAccountClient: got %+v, %v, want one for %s", ac, err, open.AccountNumber)
+	}
+
+	if a, err := c.GetAccount(closed.AccountNumber); err != nil || !a.Deactivated || a.URL != closed.URL {
+		t.Errorf("GetAccount of the deactivated account: got %+v, %v", a, err)
+	}
+	if _, err := c.GetAccount("5RH99999"); !robinhood.IsNotFound(err) {
+		t.Errorf("GetAccount of a missing account: got %v, want not found", err)
+	}
+}
+
+func TestAccountClientOrders(t *testing.T) {
+	s := newServer(t)
+	s.PageSize = 2
+	s.AddUser("bob", "hunter2", "")
+	a := s.AddAccount(robinhood.Account{})
+	b := s.AddAccount(robinhood.Account{})
+	aapl := s.AddInstrument(robinhood.Instrument{Symbol: "AAPL"})
+	msft := s.AddInstrument(robinhood.Instrument{Symbol: "MSFT"})
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+	ac, bc := c.ForAccount(a), c.ForAccount(b)
+
+	send := func(ac *robinhood.AccountClient, inst robinhood.Instrument) string {
+		t.Helper()
+		// The request names another account; SendOrder replaces it.
+		var other robinhood.Account
+		other.URL = "elsewhere"
+		o, err := ac.SendOrder(orderRequest(other, inst))
+		if err != nil {
+			t.Fatalf("SendOrder: %v", err)
+		}
+		if o.Account != ac.Account.URL {
+			t.Errorf("order sent for %s, want %s", o.Account, ac.Account.URL)
+		}
+		return o.Id
+	}
+	a1 := send(ac, aapl)
+	b1 := send(bc, aapl)
+	a2 := send(ac, msft)
+	b2 := send(bc, msft)
+	a3 := send(ac, aapl)
+
+	ids := func(orders []robinhood.Order, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("GetOrders: %v", err)
+		}
+		var ids []string
+		for _, o := range orders {
+			ids = append(ids, o.Id)
+		}
+		return strings.Join(ids, ",")
+	}
+	tests := []struct {
+		name string
+		got  string
+		want []string
+	}{
+		{"account a", ids(ac.GetOrders(nil)), []string{a3, a2, a1}},
+		{"account b", ids(bc.GetOrders(nil)), []string{b2, b1}},
+		{"account a AAPL", ids(ac.GetOrders(&aapl)), []string{a3, a1}},
+		{"account b MSFT", ids(bc.GetOrders(&msft)), []string{b2}},
+	}
+	for _, tt := range tests {
+		if want := strings.Join(tt.want, ","); tt.got != want {
+			t.Errorf("%s: got orders %s, want %s", tt.name, tt.got, want)
+		}
+	}
+}
+
+func TestUser(t *testing.T) {
+	s := newServer(t)
+	s.AddUser("bob", "hunter2", "")
+	s.SetUser(robinhood.User{ID: "u1", Username: "bob", FirstName: "Bob", Email: "bob@example.com"})
+	s.SetBasicInfo(robinhood.BasicInfo{City: "Menlo Park", State: "CA", NumberDependents: 2})
+	s.SetInvestmentProfile(robinhood.InvestmentProfile{RiskTolerance: robinhood.RiskTolerance_High})
+	s.SetEmployment(robinhood.Employment{EmploymentStatus: robinhood.EmploymentStatus_Retired})
+	c := dial(t, s, s.Creds("bob", "hunter2"))
+
+	u, err := c.GetUser()
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if u.ID != "u1" || u.Username != "bob" || u.FirstName != "Bob" || u.Email != "bob@example.com" {
+		t.Errorf("GetUser: got %+v", u)
+	}
+	for name, url := range map[string]string{
+		"basic_info":         u.BasicInfo,
+		"investment_profile": u.InvestmentProfile,
+		"employment":         u.Employment,
+	} {
+		if want := u.URL + name + "/"; url != want {
+			t.Errorf("user %s URL: got %q, want %q", name, url, want)
+		}
+	}
+
+	b, err := c.GetBasicInfo()
+	if err != nil || b.City != "Menlo Park" || b.State != "CA" || b.NumberDependents != 2 || b.User != u.URL {
+		t.Errorf("GetBasicInfo: got %+v, %v", b, err)
+	}
+	p, err := c.GetInvestmentProfile()
+	if err != nil || p.RiskTolerance != robinhood.RiskTolerance_High || p.User != u.URL {
+		t.Errorf("GetInvestmentProfile: got %+v, %v", p, err)
+	}
+	e, err := c.GetEmployment()
+	if err != nil || e.EmploymentStatus != robinhood.EmploymentStatus_Retired || e.User != u.URL {
+		t.Errorf("GetEmployment: got %+v, %v", e, err)
+	}
+}
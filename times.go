@@ -1,6 +1,9 @@
 package robinhood
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // Common constants for hours and minutes from midnight at which market events
 // occur.
@@ -25,11 +28,14 @@ func MinuteOfDay(t time.Time) int {
 	return t.Hour()*60 + t.Minute()
 }
 
-// nyLoc returns the *time.Location of New_York.
-func nyLoc() *time.Location {
-	et, _ := time.LoadLocation("America/New_York")
-	return et
-}
+// nyLoc returns the *time.Location of New_York, or a fixed EST zone if the
+// time zone database is unavailable.
+var nyLoc = sync.OnceValue(func() *time.Location {
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		return loc
+	}
+	return time.FixedZone("EST", -5*60*60)
+})
 
 // nyMinute returns the current minute after midnight in New_York.
 func nyMinute() int {
@@ -0,0 +1,100 @@
+package robinhood
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TOTP parameters used by Robinhood (and most authenticator apps).
+const (
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+)
+
+// TOTP returns the RFC 6238 time-based one-time password for the base32
+// encoded secret at time t, using HMAC-SHA1, 30 second periods and 6 digits.
+func TOTP(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, uint64(t.Unix()/int64(totpPeriod/time.Second))), nil
+}
+
+// decodeTOTPSecret decodes a base32 secret as shown by authenticator setup
+// screens, which may be lower case, space separated and unpadded.
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	s = strings.TrimRight(s, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("robinhood: invalid TOTP secret: %s", err)
+	}
+	return key, nil
+}
+
+// hotp returns the RFC 4226 one-time password for key and counter.
+func hotp(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	code := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000)
+}
+
+// A TOTPProvider is an MFAProvider that computes authenticator app codes from
+// the app's secret, so that unattended programs can log in.
+type TOTPProvider struct {
+	// Secret is the base32 encoded authenticator seed.
+	Secret string
+	// Skew is a fixed offset added to the local clock when computing codes,
+	// for a clock known to be off by more than a period. It is not
+	// adjusted automatically.
+	Skew time.Duration
+}
+
+// MFACode implements MFAProvider. The first attempt uses the code for the
+// current period. Should it be rejected, the second attempt uses the
+// previous period's code, in case the server's clock is behind, and later
+// attempts wait for the next period and use its code, in case it is ahead
+// or the period ended in transit.
+func (p *TOTPProvider) MFACode(ctx context.Context, ch *MFAChallenge) (string, error) {
+	if ch.Type != "" && ch.Type != ChallengeType_App {
+		return "", fmt.Errorf("robinhood: a TOTP secret cannot answer a %s challenge", ch.Type)
+	}
+
+	now := time.Now().Add(p.Skew)
+	switch {
+	case ch.Attempt == 2:
+		now = now.Add(-totpPeriod)
+	case ch.Attempt > 2:
+		next := now.Truncate(totpPeriod).Add(totpPeriod)
+		t := time.NewTimer(next.Sub(now))
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-t.C:
+		}
+		now = next
+	}
+	return TOTP(p.Secret, now)
+}
+
+// NewCredsWithTOTPSecret returns Creds that compute a fresh MFA code from the
+// authenticator secret each time they log in.
+func NewCredsWithTOTPSecret(username, password, secret string) *Creds {
+	c := NewCreds(username, password)
+	c.MFAProvider = &TOTPProvider{Secret: secret}
+	return c
+}
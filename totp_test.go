@@ -0,0 +1,84 @@
+package robinhood_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"astuart.co/go-robinhood"
+)
+
+// rfc6238Secret is the RFC 6238 test key "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1, truncated to 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := robinhood.TOTP(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil || got != tt.want {
+			t.Errorf("TOTP at %d: got %q, %v, want %s", tt.unix, got, err, tt.want)
+		}
+	}
+
+	// Secrets are accepted as authenticator apps show them.
+	if got, err := robinhood.TOTP("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0)); err != nil || got != "287082" {
+		t.Errorf("TOTP with a formatted secret: got %q, %v, want 287082", got, err)
+	}
+	if _, err := robinhood.TOTP("not base32!", time.Now()); err == nil {
+		t.Error("TOTP with an invalid secret succeeded")
+	}
+}
+
+func TestTOTPProvider(t *testing.T) {
+	p := &robinhood.TOTPProvider{Secret: rfc6238Secret}
+
+	// code returns the provider's code for attempt and the codes for the
+	// current and previous periods, retrying if a period ends between.
+	code := func(ctx context.Context, attempt int) (got, prev, cur string, err error) {
+		for {
+			before := time.Now().Add(p.Skew)
+			got, err = p.MFACode(ctx, &robinhood.MFAChallenge{Type: robinhood.ChallengeType_App, Attempt: attempt})
+			after := time.Now().Add(p.Skew)
+			if before.Truncate(30*time.Second) != after.Truncate(30*time.Second) {
+				continue
+			}
+			prev, _ = robinhood.TOTP(p.Secret, before.Add(-30*time.Second))
+			cur, _ = robinhood.TOTP(p.Secret, before)
+			return got, prev, cur, err
+		}
+	}
+
+	if got, _, cur, err := code(context.Background(), 1); err != nil || got != cur {
+		t.Errorf("attempt 1: got %q, %v, want the current code %s", got, err, cur)
+	}
+	if got, prev, _, err := code(context.Background(), 2); err != nil || got != prev {
+		t.Errorf("attempt 2: got %q, %v, want the previous code %s", got, err, prev)
+	}
+
+	// Later attempts wait for the next period.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.MFACode(ctx, &robinhood.MFAChallenge{Type: robinhood.ChallengeType_App, Attempt: 3}); err != context.Canceled {
+		t.Errorf("attempt 3 canceled: got %v, want context.Canceled", err)
+	}
+
+	p.Skew = -time.Hour
+	if got, _, cur, err := code(context.Background(), 1); err != nil || got != cur {
+		t.Errorf("attempt 1 with skew: got %q, %v, want the skewed code %s", got, err, cur)
+	}
+
+	if _, err := p.MFACode(context.Background(), &robinhood.MFAChallenge{Type: robinhood.ChallengeType_SMS, Attempt: 1}); err == nil {
+		t.Error("answered an SMS challenge")
+	}
+}
@@ -0,0 +1,173 @@
+package robinhood
+
+import (
+	"context"
+	"time"
+)
+
+// User endpoints, relative to the API base URL.
+const (
+	epUser              = "user/"
+	epBasicInfo         = epUser + "basic_info/"
+	epInvestmentProfile = epUser + "investment_profile/"
+	epEmployment        = epUser + "employment/"
+)
+
+// A User is the person the Client is logged in as. Account.User is its URL.
+type User struct {
+	ID                string    `json:"id"`
+	Username          string    `json:"username"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
+	IDInfo            string    `json:"id_info"`
+	BasicInfo         string    `json:"basic_info"`
+	InvestmentProfile string    `json:"investment_profile"`
+	Employment        string    `json:"employment"`
+	InternationalInfo string    `json:"international_info"`
+	AdditionalInfo    string    `json:"additional_info"`
+	CreatedAt         time.Time `json:"created_at"`
+	URL               string    `json:"url"`
+	Detail            string    `json:"detail"`
+}
+
+func (resp *User) Details() string {
+	return resp.Detail
+}
+
+// BasicInfo is the identity and contact information of a User.
+type BasicInfo struct {
+	User               string `json:"user"`
+	PhoneNumber        string `json:"phone_number"`
+	Address            string `json:"address"`
+	City               string `json:"city"`
+	State              string `json:"state"`
+	Zipcode            string `json:"zipcode"`
+	CountryOfResidence string `json:"country_of_residence"`
+	Citizenship        string `json:"citizenship"`
+	DateOfBirth        string `json:"date_of_birth"`
+	MaritalStatus      string `json:"marital_status"`
+	NumberDependents   int    `json:"number_dependents"`
+	// The last four digits of the user's SSN.
+	TaxIDSSN  string    `json:"tax_id_ssn"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Detail    string    `json:"detail"`
+}
+
+func (resp *BasicInfo) Details() string {
+	return resp.Detail
+}
+
+type RiskTolerance string
+
+const (
+	RiskTolerance_Low    RiskTolerance = "low_risk_tolerance"
+	RiskTolerance_Medium RiskTolerance = "med_risk_tolerance"
+	RiskTolerance_High   RiskTolerance = "high_risk_tolerance"
+)
+
+// An InvestmentProfile is a User's answers to Robinhood's suitability
+// questions. Amounts are given as ranges such as "25000_39999".
+type InvestmentProfile struct {
+	User                          string        `json:"user"`
+	AnnualIncome                  string        `json:"annual_income"`
+	LiquidNetWorth                string        `json:"liquid_net_worth"`
+	TotalNetWorth                 string        `json:"total_net_worth"`
+	TaxBracket                    string        `json:"tax_bracket"`
+	SourceOfFunds                 string        `json:"source_of_funds"`
+	InvestmentExperience          string        `json:"investment_experience"`
+	InvestmentExperienceCollected bool          `json:"investment_experience_collected"`
+	InvestmentObjective           string        `json:"investment_objective"`
+	OptionTradingExperience       string        `json:"option_trading_experience"`
+	ProfessionalTrader            bool          `json:"professional_trader"`
+	RiskTolerance                 RiskTolerance `json:"risk_tolerance"`
+	TimeHorizon                   string        `json:"time_horizon"`
+	LiquidityNeeds                string        `json:"liquidity_needs"`
+	SuitabilityVerified           bool          `json:"suitability_verified"`
+	UnderstandOptionSpreads       bool          `json:"understand_option_spreads"`
+	UpdatedAt                     time.Time     `json:"updated_at"`
+	Detail                        string        `json:"detail"`
+}
+
+func (resp *InvestmentProfile) Details() string {
+	return resp.Detail
+}
+
+type EmploymentStatus string
+
+const (
+	EmploymentStatus_Employed     EmploymentStatus = "employed"
+	EmploymentStatus_Unemployed   EmploymentStatus = "unemployed"
+	EmploymentStatus_Retired      EmploymentStatus = "retired"
+	EmploymentStatus_Student      EmploymentStatus = "student"
+	EmploymentStatus_SelfEmployed EmploymentStatus = "self_employed"
+)
+
+// Employment is a User's employment information.
+type Employment struct {
+	User             string           `json:"user"`
+	EmploymentStatus EmploymentStatus `json:"employment_status"`
+	EmployerName     string           `json:"employer_name"`
+	EmployerAddress  string           `json:"employer_address"`
+	EmployerCity     string           `json:"employer_city"`
+	EmployerState    string           `json:"employer_state"`
+	EmployerZipcode  string           `json:"employer_zipcode"`
+	Occupation       string           `json:"occupation"`
+	YearsEmployed    int              `json:"years_employed"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Detail           string           `json:"detail"`
+}
+
+func (resp *Employment) Details() string {
+	return resp.Detail
+}
+
+// GetUser returns the user the Client is logged in as.
+func (c *Client) GetUser() (User, error) {
+	return c.GetUserContext(context.Background())
+}
+
+// GetUserContext is like GetUser but the request is bound to ctx.
+func (c *Client) GetUserContext(ctx context.Context) (User, error) {
+	var u User
+	err := c.GetAndDecodeContext(ctx, c.url(epUser), &u)
+	return u, err
+}
+
+// GetBasicInfo returns the user's basic information.
+func (c *Client) GetBasicInfo() (BasicInfo, error) {
+	return c.GetBasicInfoContext(context.Background())
+}
+
+// GetBasicInfoContext is like GetBasicInfo but the request is bound to ctx.
+func (c *Client) GetBasicInfoContext(ctx context.Context) (BasicInfo, error) {
+	var b BasicInfo
+	err := c.GetAndDecodeContext(ctx, c.url(epBasicInfo), &b)
+	return b, err
+}
+
+// GetInvestmentProfile returns the user's investment profile.
+func (c *Client) GetInvestmentProfile() (InvestmentProfile, error) {
+	return c.GetInvestmentProfileContext(context.Background())
+}
+
+// GetInvestmentProfileContext is like GetInvestmentProfile but the request is
+// bound to ctx.
+func (c *Client) GetInvestmentProfileContext(ctx context.Context) (InvestmentProfile, error) {
+	var p InvestmentProfile
+	err := c.GetAndDecodeContext(ctx, c.url(epInvestmentProfile), &p)
+	return p, err
+}
+
+// GetEmployment returns the user's employment information.
+func (c *Client) GetEmployment() (Employment, error) {
+	return c.GetEmploymentContext(context.Background())
+}
+
+// GetEmploymentContext is like GetEmployment but the request is bound to ctx.
+func (c *Client) GetEmploymentContext(ctx context.Context) (Employment, error) {
+	var e Employment
+	err := c.GetAndDecodeContext(ctx, c.url(epEmployment), &e)
+	return e, err
+}
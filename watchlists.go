@@ -1,7 +1,7 @@
 package robinhood
 
 import (
-	"sync"
+	"context"
 )
 
 // A Watchlist is a list of stock Instruments that an investor is tracking in
@@ -25,17 +25,19 @@ func (resp *GetWatchListResults) Details() string {
 
 // GetWatchlists retrieves the watchlists for a given set of credentials/accounts.
 func (c *Client) GetWatchlists() ([]Watchlist, error) {
-	var r GetWatchListResults
-	err := c.GetAndDecode(epWatchlists, &r)
+	return c.GetWatchlistsContext(context.Background())
+}
+
+// GetWatchlistsContext is like GetWatchlists but the request is bound to ctx.
+func (c *Client) GetWatchlistsContext(ctx context.Context) ([]Watchlist, error) {
+	lists, err := NewPager[Watchlist](c, c.url(epWatchlists)).All(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if r.Results != nil {
-		for i := range r.Results {
-			r.Results[i].Client = c
-		}
+	for i := range lists {
+		lists[i].Client = c
 	}
-	return r.Results, nil
+	return lists, nil
 }
 
 type GetInstrumentsResponse2 struct {
@@ -53,31 +55,26 @@ type Instrument2 struct {
 
 // GetInstruments returns the list of Instruments associated with a Watchlist.
 func (w *Watchlist) GetInstruments() ([]Instrument, error) {
-	var r GetInstrumentsResponse2
+	return w.GetInstrumentsContext(context.Background())
+}
 
-	err := w.Client.GetAndDecode(w.URL, &r)
+// GetInstrumentsContext is like GetInstruments but all requests, including
+// the per-instrument lookups, are bound to ctx. If ctx is done before every
+// lookup completes, ctx.Err() is returned.
+func (w *Watchlist) GetInstrumentsContext(ctx context.Context) ([]Instrument, error) {
+	items, err := NewPager[Instrument2](w.Client, w.URL).All(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	insts := make([]*Instrument, len(r.Results))
-	wg := &sync.WaitGroup{}
-	wg.Add(len(r.Results))
-
-	for i := range r.Results {
-		go func(i int) {
-			defer wg.Done()
-
-			inst, err := w.Client.GetInstrument(r.Results[i].Instrument)
-			if err != nil {
-				return
-			}
-
-			insts[i] = inst
-		}(i)
+	urls := make([]string, len(items))
+	for i := range items {
+		urls[i] = items[i].Instrument
+	}
+	insts, _ := w.Client.getInstruments(ctx, urls)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-
-	wg.Wait()
 
 	// Filter slice for empties (if error)
 	var retInsts []Instrument